@@ -0,0 +1,411 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/shenwei356/breader"
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/client"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+	"github.com/spf13/cobra"
+)
+
+// annotateCmd represents the annotate command
+var annotateCmd = &cobra.Command{
+	Use:   "annotate",
+	Short: "annotate tabular files (e.g. BLAST outfmt 6) with TaxId and lineage",
+	Long: `annotate tabular files (e.g. BLAST/DIAMOND outfmt 6) with TaxId and lineage.
+
+The column of GI or accession (--field) is resolved to TaxId, and four
+columns are appended to every line in input order:
+
+    TaxId, scientific name, rank, lineage
+
+"NA" is printed for IDs not resolved.
+
+Supported database types (-t/--type):
+
+    gi_taxid_nucl      GI (nucl)
+    gi_taxid_prot      GI (prot)
+    acc_taxid_nucl     accession (nucl)
+    acc_taxid_prot     accession (prot)
+
+IDs like "gi|139299181|ref|YP_001.1|" are recognized automatically,
+or use --id-regexp to capture the ID from the column.
+
+With --lca, one line of Lowest Common Ancestor is reported for every
+query (-q/--query-field) over all its hits, in order of first appearance:
+
+    query, number of hits, TaxId, scientific name, rank, lineage
+
+where hits not resolved to TaxId are not counted.
+
+Names and nodes are loaded into memory in local mode,
+use --remote to query from a running "gtaxon server" instead.
+
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbType, err := cmd.Flags().GetString("type")
+		checkError(err)
+		field, err := cmd.Flags().GetInt("field")
+		checkError(err)
+		queryField, err := cmd.Flags().GetInt("query-field")
+		checkError(err)
+		idRegexpStr, err := cmd.Flags().GetString("id-regexp")
+		checkError(err)
+		doLCA, err := cmd.Flags().GetBool("lca")
+		checkError(err)
+		remote, err := cmd.Flags().GetBool("remote")
+		checkError(err)
+		chunkSize, err := cmd.Flags().GetInt("chunk-size")
+		checkError(err)
+		threads, err := cmd.Flags().GetInt("threads")
+		checkError(err)
+
+		switch dbType {
+		case "gi_taxid_nucl", "gi_taxid_prot", "acc_taxid_nucl", "acc_taxid_prot":
+		case "":
+			log.Error("Flag -t/--type needed")
+			os.Exit(-1)
+		default:
+			log.Errorf("Unsupported data type: %s", dbType)
+			os.Exit(-1)
+		}
+		if field <= 0 || queryField <= 0 {
+			log.Error("Column number (--field and -q/--query-field) should be positive")
+			os.Exit(-1)
+		}
+		if len(args) == 0 {
+			args = []string{"-"}
+		}
+
+		var idRegexp *regexp.Regexp
+		if idRegexpStr != "" {
+			idRegexp, err = regexp.Compile(idRegexpStr)
+			checkError(err)
+			if idRegexp.NumSubexp() < 1 {
+				log.Error("--id-regexp should contain a capture group, e.g. \"gi\\|(\\d+)\"")
+				os.Exit(-1)
+			}
+		}
+		extract := idExtractor(dbType, idRegexp)
+
+		var q client.Querier
+		if remote {
+			servers := getServerURLs(cmd)
+			log.Infof("Annotate with database: %s from server: %s", dbType, strings.Join(servers, ", "))
+			q = newRemoteQuerier(cmd, "rest", servers)
+		} else {
+			log.Infof("Annotate with database: %s", dbType)
+			dbFilePath, _, _ := getDbFilePath(cmd)
+			q, err = client.NewLocal(dbFilePath, threads)
+			checkError(err)
+		}
+		defer q.Close()
+		a := newAnnotator(q, dbType)
+
+		outfh := bufio.NewWriter(os.Stdout)
+		defer outfh.Flush()
+
+		if doLCA {
+			a.lcaOfQueries(outfh, args, queryField, field, extract, chunkSize)
+		} else {
+			a.annotate(outfh, args, field, extract, chunkSize)
+		}
+	},
+}
+
+// annotator resolves IDs to TaxIds and Taxons, and caches Taxons
+type annotator struct {
+	q      client.Querier
+	dbType string
+
+	cache map[string]nodes.Taxon
+}
+
+func newAnnotator(q client.Querier, dbType string) *annotator {
+	return &annotator{q: q, dbType: dbType, cache: make(map[string]nodes.Taxon)}
+}
+
+// resolve queries TaxIds of IDs
+func (a *annotator) resolve(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+	return a.q.Gi2TaxID(context.Background(), a.dbType, ids)
+}
+
+// lcas queries LCAs of groups of taxids, groups failed
+// (e.g., none of the taxids exists in nodes) are left empty
+func (a *annotator) lcas(queries [][]string) []nodes.Taxon {
+	if len(queries) == 0 {
+		return []nodes.Taxon{}
+	}
+	lcas, err := a.q.LCA(context.Background(), queries)
+	if err == nil {
+		return lcas
+	}
+
+	lcas = make([]nodes.Taxon, len(queries))
+	for i, taxids := range queries {
+		lca, err := a.q.LCA(context.Background(), [][]string{taxids})
+		if err != nil {
+			log.Warningf("failed to query LCA of %s: %s", strings.Join(taxids, ","), err)
+			continue
+		}
+		lcas[i] = lca[0]
+	}
+	return lcas
+}
+
+// taxonsOf returns Taxons of taxids, using and updating the cache
+func (a *annotator) taxonsOf(taxids []string) map[string]nodes.Taxon {
+	missing := []string{}
+	seen := make(map[string]struct{})
+	for _, taxid := range taxids {
+		if taxid == "" {
+			continue
+		}
+		if _, ok := a.cache[taxid]; ok {
+			continue
+		}
+		if _, ok := seen[taxid]; ok {
+			continue
+		}
+		seen[taxid] = struct{}{}
+		missing = append(missing, taxid)
+	}
+	if len(missing) > 0 {
+		taxons, err := a.q.TaxID2Taxon(context.Background(), missing)
+		checkError(err)
+		for i, taxid := range missing {
+			a.cache[taxid] = taxons[i]
+		}
+	}
+	return a.cache
+}
+
+func (a *annotator) annotate(outfh *bufio.Writer, files []string, field int, extract func(string) string, chunkSize int) {
+	for _, file := range files {
+		reader, err := breader.NewBufferedReader(file, 2, chunkSize, annotateLineFn)
+		checkError(err)
+
+		for chunk := range reader.Ch {
+			checkError(chunk.Err)
+
+			ids := make([]string, 0, len(chunk.Data))
+			for _, data := range chunk.Data {
+				line := data.(string)
+				if line[0] == '#' {
+					continue
+				}
+				ids = append(ids, extract(columnOf(line, field)))
+			}
+			taxids, err := a.resolve(ids)
+			checkError(err)
+			taxons := a.taxonsOf(taxids)
+
+			i := 0
+			for _, data := range chunk.Data {
+				line := data.(string)
+				if line[0] == '#' {
+					fmt.Fprintln(outfh, line)
+					continue
+				}
+				taxid := taxids[i]
+				i++
+				fmt.Fprintf(outfh, "%s\t%s\n", line, taxonColumns(taxid, taxons[taxid]))
+			}
+		}
+	}
+}
+
+func (a *annotator) lcaOfQueries(outfh *bufio.Writer, files []string, queryField int, field int, extract func(string) string, chunkSize int) {
+	queries := []string{}
+	hits := make(map[string]int)
+	taxidsOfQuery := make(map[string][]string)
+	seen := make(map[string]map[string]struct{})
+
+	for _, file := range files {
+		reader, err := breader.NewBufferedReader(file, 2, chunkSize, annotateLineFn)
+		checkError(err)
+
+		for chunk := range reader.Ch {
+			checkError(chunk.Err)
+
+			qs := make([]string, 0, len(chunk.Data))
+			ids := make([]string, 0, len(chunk.Data))
+			for _, data := range chunk.Data {
+				line := data.(string)
+				if line[0] == '#' {
+					continue
+				}
+				qs = append(qs, columnOf(line, queryField))
+				ids = append(ids, extract(columnOf(line, field)))
+			}
+			taxids, err := a.resolve(ids)
+			checkError(err)
+
+			for i, q := range qs {
+				if _, ok := seen[q]; !ok {
+					queries = append(queries, q)
+					seen[q] = make(map[string]struct{})
+				}
+				taxid := taxids[i]
+				if taxid == "" {
+					continue
+				}
+				hits[q]++
+				if _, ok := seen[q][taxid]; ok {
+					continue
+				}
+				seen[q][taxid] = struct{}{}
+				taxidsOfQuery[q] = append(taxidsOfQuery[q], taxid)
+			}
+		}
+	}
+
+	for start := 0; start < len(queries); start += chunkSize {
+		end := start + chunkSize
+		if end > len(queries) {
+			end = len(queries)
+		}
+
+		// LCA is only computed for queries with more than one TaxId
+		lcaQueries := [][]string{}
+		for _, q := range queries[start:end] {
+			if len(taxidsOfQuery[q]) > 1 {
+				lcaQueries = append(lcaQueries, taxidsOfQuery[q])
+			}
+		}
+		lcas := a.lcas(lcaQueries)
+		singles := []string{}
+		for _, q := range queries[start:end] {
+			if len(taxidsOfQuery[q]) == 1 {
+				singles = append(singles, taxidsOfQuery[q][0])
+			}
+		}
+		taxons := a.taxonsOf(singles)
+
+		j := 0
+		for _, q := range queries[start:end] {
+			var t nodes.Taxon
+			switch len(taxidsOfQuery[q]) {
+			case 0:
+			case 1:
+				t = taxons[taxidsOfQuery[q][0]]
+			default:
+				t = lcas[j]
+				j++
+			}
+			taxid := ""
+			if t.TaxId > 0 {
+				taxid = fmt.Sprintf("%d", t.TaxId)
+			}
+			fmt.Fprintf(outfh, "%s\t%d\t%s\n", q, hits[q], taxonColumns(taxid, t))
+		}
+	}
+}
+
+// taxonColumns returns columns of TaxId, scientific name, rank and lineage,
+// "NA" for missing TaxId or Taxon
+func taxonColumns(taxid string, t nodes.Taxon) string {
+	if taxid == "" {
+		return strings.Join([]string{notFound, notFound, notFound, notFound}, "\t")
+	}
+	if t.TaxId == 0 {
+		return strings.Join([]string{taxid, notFound, notFound, notFound}, "\t")
+	}
+	return strings.Join([]string{taxid, t.ScientificName, t.Rank, t.Lineage}, "\t")
+}
+
+func annotateLineFn(line string) (interface{}, bool, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", false, nil
+	}
+	return line, true, nil
+}
+
+// columnOf returns the n-th (1-based) tab-delimited column of a line
+func columnOf(line string, n int) string {
+	items := strings.SplitN(line, "\t", n+1)
+	if len(items) < n {
+		return ""
+	}
+	return items[n-1]
+}
+
+var accDbTags = map[string]struct{}{
+	"gb": {}, "emb": {}, "dbj": {}, "ref": {}, "sp": {}, "tr": {},
+	"pir": {}, "prf": {}, "pdb": {}, "tpg": {}, "tpe": {}, "tpd": {},
+}
+
+// idExtractor returns function to extract GI or accession from a column,
+// e.g. "gi|139299181|ref|YP_001.1|"
+func idExtractor(dbType string, idRegexp *regexp.Regexp) func(string) string {
+	if idRegexp != nil {
+		return func(s string) string {
+			found := idRegexp.FindStringSubmatch(s)
+			if found == nil {
+				return s
+			}
+			return found[1]
+		}
+	}
+	isAcc := taxon.IsAccBucket(dbType)
+	return func(s string) string {
+		if !strings.Contains(s, "|") {
+			return s
+		}
+		items := strings.Split(s, "|")
+		for i := 0; i < len(items)-1; i++ {
+			if isAcc {
+				if _, ok := accDbTags[items[i]]; ok {
+					return items[i+1]
+				}
+			} else if items[i] == "gi" {
+				return items[i+1]
+			}
+		}
+		return s
+	}
+}
+
+func init() {
+	cliCmd.AddCommand(annotateCmd)
+
+	annotateCmd.Flags().StringP("type", "t", "", "database type (see introduction)")
+	annotateCmd.Flags().IntP("field", "", 2, "column number of GI or accession (1-based)")
+	annotateCmd.Flags().IntP("query-field", "q", 1, "column number of query ID (1-based, only for --lca)")
+	annotateCmd.Flags().StringP("id-regexp", "r", "", `regular expression with a capture group to extract ID from the column`)
+	annotateCmd.Flags().BoolP("lca", "L", false, "report Lowest Common Ancestor of all hits for every query")
+	annotateCmd.Flags().BoolP("remote", "", false, "query from remote server instead of local database")
+	addServerFlags(annotateCmd, " (only for --remote)")
+	annotateCmd.Flags().IntP("chunk-size", "c", 10000, "chunk size of querying")
+}
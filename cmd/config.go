@@ -0,0 +1,253 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "configuration from config file and environment variables",
+	Long: `configuration from config file and environment variables.
+
+Every flag could also be set in config file (--config, or environment
+variable GTAXON_CONFIG) or by environment variable "GTAXON_<FLAG>", e.g.,
+--db-dir by "db-dir: /data/gtaxon" or GTAXON_DB_DIR=/data/gtaxon.
+Settings could be limited to a command by its path, e.g., --port of
+"gtaxon server" by
+
+    server:
+      port: 8080
+
+or GTAXON_SERVER_PORT=8080, while "port: 8080" or GTAXON_PORT=8080
+applies to all commands with flag --port.
+
+Precedence, from the highest:
+
+    1. command line flags
+    2. settings of the command, e.g., "server.port" then "port"
+       for "gtaxon server" (environment variable before config file)
+    3. settings of parent commands, e.g., "cli.threads"
+    4. global settings, e.g., "threads"
+    5. default values of flags
+
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			log.Error("Command needed. Type \"gtaxon config -h\" for help")
+		}
+	},
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show [command]",
+	Short: "print effective configuration",
+	Long: `print effective configuration in format of config file.
+
+Values of all flags are printed with their sources, grouped by the
+commands defining them. If a command is given, e.g.,
+"gtaxon config show cli remote", all flags of the command are printed,
+including those of parent commands. Limits of "gtaxon server" without
+flags (keys-file, key-rate, key-burst, ip-rate, ip-burst and max-batch)
+are printed along with its flags.
+
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if viper.ConfigFileUsed() != "" {
+			if _, err := os.Stat(viper.ConfigFileUsed()); err == nil {
+				fmt.Printf("# config file: %s\n", viper.ConfigFileUsed())
+			} else {
+				fmt.Printf("# config file: %s (not found)\n", viper.ConfigFileUsed())
+			}
+		}
+
+		if len(args) > 0 {
+			target, rest, err := RootCmd.Find(args)
+			checkError(err)
+			if len(rest) > 0 || target == RootCmd {
+				checkError(fmt.Errorf("unknown command: %s", strings.Join(args, " ")))
+			}
+			target.InheritedFlags() // merges flags of parent commands into Flags()
+			target.Flags().VisitAll(func(f *pflag.Flag) {
+				printSetting(target, f, 0)
+			})
+			if target == serverCmd {
+				printServerSettings(0)
+			}
+			return
+		}
+		printSettings(RootCmd, 0)
+	},
+}
+
+// configPath returns path of command in config file,
+// e.g., ["cli", "remote"] for "gtaxon cli remote"
+func configPath(cmd *cobra.Command) []string {
+	path := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		path = append([]string{c.Name()}, path...)
+	}
+	return path
+}
+
+// lookupConfig returns value of flag of command from environment variables
+// or config file, and its source. Keys are tried from the most specific one,
+// e.g., "cli.remote.host", "cli.host" and "host" for --host of "gtaxon cli remote".
+func lookupConfig(cmd *cobra.Command, name string) (string, string, bool) {
+	path := configPath(cmd)
+	for i := len(path); i >= 0; i-- {
+		key := strings.Join(append(append([]string{}, path[:i]...), name), ".")
+		if value, source, ok := lookupConfigKey(key); ok {
+			return value, source, true
+		}
+	}
+	return "", "", false
+}
+
+// lookupConfigKey returns value of key from environment variables
+// or config file, and its source. Sections (maps) are skipped.
+func lookupConfigKey(key string) (string, string, bool) {
+	env := "GTAXON_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if value, ok := os.LookupEnv(env); ok {
+		return value, "env " + env, true
+	}
+	if viper.IsSet(key) {
+		if _, ok := viper.Get(key).(map[string]interface{}); ok { // section of a command, e.g., "cli.remote"
+			return "", "", false
+		}
+		return viper.GetString(key), "config " + key, true
+	}
+	return "", "", false
+}
+
+// applyConfig sets flags of command not given in command line
+// from environment variables or config file
+func applyConfig(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || f.Name == "config" || f.Name == "help" {
+			return
+		}
+		value, source, ok := lookupConfig(cmd, f.Name)
+		if !ok {
+			return
+		}
+		if e := f.Value.Set(value); e != nil {
+			err = fmt.Errorf("invalid value of flag --%s from %s: %s", f.Name, source, e)
+		}
+	})
+	return err
+}
+
+// printSettings prints settings of flags defined by command and its
+// subcommands, indented by depth
+func printSettings(cmd *cobra.Command, depth int) {
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.AddFlagSet(cmd.PersistentFlags())
+	flags.AddFlagSet(cmd.LocalNonPersistentFlags())
+	flags.VisitAll(func(f *pflag.Flag) {
+		printSetting(cmd, f, depth)
+	})
+	if cmd == serverCmd {
+		printServerSettings(depth)
+	}
+
+	for _, c := range cmd.Commands() {
+		if !c.IsAvailableCommand() || c == configCmd {
+			continue
+		}
+		fmt.Printf("%s%s:\n", strings.Repeat("  ", depth), c.Name())
+		printSettings(c, depth+1)
+	}
+}
+
+// printSetting prints effective value of flag of command and its source
+func printSetting(cmd *cobra.Command, f *pflag.Flag, depth int) {
+	if f.Name == "config" || f.Name == "help" {
+		return
+	}
+	value, source, ok := lookupConfig(cmd, f.Name)
+	if !ok {
+		value, source = f.DefValue, "default"
+	}
+	if f.Value.Type() == "string" {
+		value = strconv.Quote(value)
+	}
+	fmt.Printf("%s%s: %s # %s\n", strings.Repeat("  ", depth), f.Name, value, source)
+}
+
+// serverSettings are settings of "gtaxon server" only in config file
+// or environment variables, except max-batch only in config file
+var serverSettings = []struct {
+	name     string
+	defValue string
+}{
+	{"keys-file", `""`},
+	{"key-rate", "0"},
+	{"key-burst", "0"},
+	{"ip-rate", "0"},
+	{"ip-burst", "0"},
+}
+
+// printServerSettings prints limits of "gtaxon server" without flags,
+// indented by depth
+func printServerSettings(depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, setting := range serverSettings {
+		value, source, ok := lookupConfigKey("server." + setting.name)
+		if !ok {
+			value, source = setting.defValue, "default"
+		} else if setting.name == "keys-file" {
+			value = strconv.Quote(value)
+		}
+		fmt.Printf("%s%s: %s # %s\n", indent, setting.name, value, source)
+	}
+
+	maxBatch := viper.GetStringMap("server.max-batch")
+	if len(maxBatch) == 0 {
+		fmt.Printf("%smax-batch: {} # default\n", indent)
+		return
+	}
+	fmt.Printf("%smax-batch: # config server.max-batch\n", indent)
+	endpoints := make([]string, 0, len(maxBatch))
+	for endpoint := range maxBatch {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		fmt.Printf("%s  %s: %v\n", indent, endpoint, maxBatch[endpoint])
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+}
@@ -0,0 +1,265 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/shenwei356/breader"
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/spf13/cobra"
+)
+
+// fastaTaxidCmd represents the fasta-taxid command
+var fastaTaxidCmd = &cobra.Command{
+	Use:   "fasta-taxid",
+	Short: "resolve TaxIds of organisms in FASTA headers",
+	Long: `resolve TaxIds of organisms in FASTA headers (local database).
+
+Supported header conventions, in order of priority:
+
+    UniProt    >sp|P69905|HBA_HUMAN Hemoglobin subunit alpha OS=Homo sapiens OX=9606 GN=HBA1
+    NCBI nr    >NP_000549.1 hemoglobin subunit alpha [Homo sapiens]
+
+TaxIds given by OX= are checked against nodes, and organism names given by
+OS= or the trailing [...] are resolved case-insensitively with all name classes.
+
+By default, a seqid-taxid map is outputted (e.g. for building Kraken and
+Centrifuge databases). Use -w/--rewrite to append "taxid=" tags to headers
+and output the whole sequences.
+
+Unresolved and ambiguous organisms are reported with suggestions
+to stderr or to the file given by -u/--unresolved, so are TaxIds of OX=
+not found in nodes (e.g. merged or deleted ones) without OS=.
+
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rewrite, err := cmd.Flags().GetBool("rewrite")
+		checkError(err)
+		unresolvedFile, err := cmd.Flags().GetString("unresolved")
+		checkError(err)
+		chunkSize, err := cmd.Flags().GetInt("chunk-size")
+		checkError(err)
+		threads, err := cmd.Flags().GetInt("threads")
+		checkError(err)
+
+		if len(args) == 0 {
+			args = []string{"-"}
+		}
+
+		dbFilePath, _, _ := getDbFilePath(cmd)
+		pool, err := taxon.NewDBPool(dbFilePath, threads)
+		checkError(err)
+		t, err := taxon.LoadTaxonomy(pool)
+		checkError(err)
+		r := newHeaderResolver(t)
+
+		outfh := bufio.NewWriter(os.Stdout)
+		defer outfh.Flush()
+
+		fn := func(line string) (interface{}, bool, error) {
+			line = strings.TrimRight(line, "\r\n")
+			if line == "" {
+				return "", false, nil
+			}
+			if !rewrite && line[0] != '>' {
+				return "", false, nil
+			}
+			return line, true, nil
+		}
+
+		var nSeqs, nResolved int
+		for _, file := range args {
+			reader, err := breader.NewBufferedReader(file, 2, chunkSize, fn)
+			checkError(err)
+
+			for chunk := range reader.Ch {
+				checkError(chunk.Err)
+
+				for _, data := range chunk.Data {
+					line := data.(string)
+					if line[0] != '>' {
+						fmt.Fprintln(outfh, line)
+						continue
+					}
+
+					nSeqs++
+					seqid, taxid := r.resolve(line[1:])
+					if taxid != "" {
+						nResolved++
+					}
+					if rewrite {
+						if taxid == "" {
+							fmt.Fprintln(outfh, line)
+						} else {
+							fmt.Fprintf(outfh, "%s taxid=%s\n", line, taxid)
+						}
+					} else if taxid != "" {
+						fmt.Fprintf(outfh, "%s\t%s\n", seqid, taxid)
+					}
+				}
+			}
+		}
+
+		log.Infof("%d of %d sequences resolved", nResolved, nSeqs)
+		r.report(unresolvedFile)
+	},
+}
+
+var reUniProtOX = regexp.MustCompile(`\bOX=(\d+)`)
+var reUniProtOS = regexp.MustCompile(`\bOS=(.+?)(\s+[A-Z]{2}=|$)`)
+var reNCBIOrganism = regexp.MustCompile(`\[([^\[\]]+)\]\s*$`)
+
+// headerResolver resolves TaxIds of FASTA headers, and records unresolved organisms
+type headerResolver struct {
+	t     *taxon.Taxonomy
+	cache map[string]string // organism -> taxid
+
+	unresolved      []string // in order of first appearance
+	unresolvedCount map[string]int
+	reasons         map[string]string // reasons of unresolved entries not being organisms
+}
+
+func newHeaderResolver(t *taxon.Taxonomy) *headerResolver {
+	return &headerResolver{
+		t:               t,
+		cache:           make(map[string]string),
+		unresolvedCount: make(map[string]int),
+		reasons:         make(map[string]string),
+	}
+}
+
+// resolve returns seqid and taxid of a header (without ">")
+func (r *headerResolver) resolve(header string) (string, string) {
+	// NCBI nr joins headers of identical sequences with Ctrl-A
+	header = strings.SplitN(header, "\x01", 2)[0]
+
+	seqid := header
+	if i := strings.IndexAny(header, " \t"); i >= 0 {
+		seqid = header[:i]
+	}
+
+	var ox string
+	if found := reUniProtOX.FindStringSubmatch(header); found != nil {
+		if _, ok := r.t.Nodes[found[1]]; ok {
+			return seqid, found[1]
+		}
+		ox = found[1] // e.g., merged or deleted taxid
+	}
+
+	var organism string
+	if found := reUniProtOS.FindStringSubmatch(header); found != nil {
+		organism = strings.TrimSpace(found[1])
+	} else if found := reNCBIOrganism.FindStringSubmatch(header); found != nil {
+		organism = strings.TrimSpace(found[1])
+	}
+	if organism == "" {
+		if ox != "" {
+			r.addUnresolved("OX="+ox, "taxid not found")
+		}
+		return seqid, ""
+	}
+
+	if taxid, ok := r.cache[organism]; ok {
+		if taxid == "" {
+			r.unresolvedCount[organism]++
+		}
+		return seqid, taxid
+	}
+
+	taxid := ""
+	if taxids := r.t.NameIndex.TaxIDs(organism); len(taxids) == 1 {
+		taxid = taxids[0]
+	} else {
+		r.addUnresolved(organism, "")
+	}
+	r.cache[organism] = taxid
+	return seqid, taxid
+}
+
+// addUnresolved records an unresolved entry, reason is computed
+// in report if not given
+func (r *headerResolver) addUnresolved(entry string, reason string) {
+	if r.unresolvedCount[entry] == 0 {
+		r.unresolved = append(r.unresolved, entry)
+		if reason != "" {
+			r.reasons[entry] = reason
+		}
+	}
+	r.unresolvedCount[entry]++
+}
+
+// report outputs unresolved organisms and suggestions to file or log
+func (r *headerResolver) report(file string) {
+	if len(r.unresolved) == 0 {
+		return
+	}
+	log.Warningf("%d organisms or TaxIds unresolved", len(r.unresolved))
+
+	var outfh *bufio.Writer
+	if file != "" {
+		fh, err := os.Create(file)
+		checkError(err)
+		defer fh.Close()
+		outfh = bufio.NewWriter(fh)
+		defer outfh.Flush()
+	}
+
+	for _, organism := range r.unresolved {
+		reason, ok := r.reasons[organism]
+		candidates := []string{}
+		switch {
+		case ok:
+		case len(r.t.NameIndex.TaxIDs(organism)) > 1:
+			reason = "ambiguous"
+			candidates = r.t.NameIndex.TaxIDs(organism)
+		default:
+			reason = "not found"
+			for _, name := range r.t.NameIndex.Suggest(organism) {
+				candidates = append(candidates, r.t.NameIndex.TaxIDs(name)...)
+			}
+		}
+		suggestions := make([]string, len(candidates))
+		for i, taxid := range candidates {
+			suggestions[i] = fmt.Sprintf("%s(%s)", taxid, r.t.ScientificName(taxid))
+		}
+
+		if outfh == nil {
+			log.Warningf("%s: %s (%d sequences). suggestions: %s", reason, organism,
+				r.unresolvedCount[organism], strings.Join(suggestions, ","))
+		} else {
+			fmt.Fprintf(outfh, "%s\t%s\t%d\t%s\n", organism, reason,
+				r.unresolvedCount[organism], strings.Join(suggestions, ","))
+		}
+	}
+}
+
+func init() {
+	cliCmd.AddCommand(fastaTaxidCmd)
+
+	fastaTaxidCmd.Flags().BoolP("rewrite", "w", false, `append "taxid=" tags to headers and output sequences`)
+	fastaTaxidCmd.Flags().StringP("unresolved", "u", "", "write unresolved organisms (name, reason, count, suggestions) to file")
+	fastaTaxidCmd.Flags().IntP("chunk-size", "c", 10000, "chunk size of reading lines")
+}
@@ -0,0 +1,121 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// newTestResolver returns headerResolver of a Taxonomy with
+// Homo sapiens (9606), and two taxa named Bacillus (1386, 55087)
+func newTestResolver() *headerResolver {
+	nods := make(map[string]nodes.Node)
+	names := make(map[string]nodes.Name)
+	for taxid, name := range map[string]string{
+		"1":     "root",
+		"9606":  "Homo sapiens",
+		"1386":  "Bacillus",
+		"55087": "Bacillus",
+	} {
+		nods[taxid] = nodes.Node{TaxID: taxid, PTaxID: "1"}
+		names[taxid] = nodes.Name{TaxID: taxid, Names: []nodes.NameItem{{Name: name, NameClass: "scientific name"}}}
+	}
+	return newHeaderResolver(taxon.NewTaxonomy(nods, names, nil, nil))
+}
+
+func TestHeaderResolverResolve(t *testing.T) {
+	r := newTestResolver()
+	for _, test := range []struct {
+		header, seqid, taxid string
+	}{
+		// UniProt
+		{"sp|P69905|HBA_HUMAN Hemoglobin subunit alpha OS=Homo sapiens OX=9606 GN=HBA1 PE=1 SV=2", "sp|P69905|HBA_HUMAN", "9606"},
+		{"tr|A0A000|A0A000_HUMAN Protein OS=Homo sapiens OX=99999 GN=X", "tr|A0A000|A0A000_HUMAN", "9606"}, // OX not found, by OS
+		{"tr|A0A001|A0A001_9XXX Protein OX=99999 GN=X", "tr|A0A001|A0A001_9XXX", ""},
+		{"tr|A0A002|A0A002_9XXX Protein OX=99999", "tr|A0A002|A0A002_9XXX", ""},
+		{"tr|A0A003|A0A003_9XXX Protein OS=Unknown bacterium OX=88888", "tr|A0A003|A0A003_9XXX", ""},
+
+		// NCBI nr
+		{"NP_000549.1 hemoglobin subunit alpha [Homo sapiens]", "NP_000549.1", "9606"},
+		{"NP_000550.1 hemoglobin [homo sapiens]\x01XP_000001.1 hemoglobin [Pan troglodytes]", "NP_000550.1", "9606"},
+		{"WP_000001.1 protein [Bacillus]", "WP_000001.1", ""},
+		{"WP_000002.1 protein [Unknown bacterium]", "WP_000002.1", ""},
+
+		// no organism
+		{"seq1 some protein", "seq1", ""},
+	} {
+		seqid, taxid := r.resolve(test.header)
+		if seqid != test.seqid || taxid != test.taxid {
+			t.Errorf("%q: got %s %s, want %s %s", test.header, seqid, taxid, test.seqid, test.taxid)
+		}
+	}
+
+	want := []string{"OX=99999", "Unknown bacterium", "Bacillus"}
+	if strings.Join(r.unresolved, "|") != strings.Join(want, "|") {
+		t.Errorf("got unresolved %q, want %q", r.unresolved, want)
+	}
+	for entry, n := range map[string]int{"OX=99999": 2, "Unknown bacterium": 2, "Bacillus": 1} {
+		if r.unresolvedCount[entry] != n {
+			t.Errorf("count of %s: got %d, want %d", entry, r.unresolvedCount[entry], n)
+		}
+	}
+}
+
+func TestHeaderResolverReport(t *testing.T) {
+	r := newTestResolver()
+	for _, header := range []string{
+		"tr|A0A001|A0A001_9XXX Protein OX=99999 GN=X",
+		"WP_000001.1 protein [Bacillus]",
+		"WP_000002.1 protein [Unknown bacterium]",
+	} {
+		r.resolve(header)
+	}
+
+	file := filepath.Join(t.TempDir(), "unresolved.tsv")
+	r.report(file)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	want := []string{
+		"OX=99999\ttaxid not found\t1\t",
+		"Bacillus\tambiguous\t1\t",
+		"Unknown bacterium\tnot found\t1\t",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got report:\n%s", data)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d: got %q, want prefix %q", i+1, lines[i], prefix)
+		}
+	}
+	if !strings.Contains(lines[1], "1386(Bacillus)") || !strings.Contains(lines[1], "55087(Bacillus)") {
+		t.Errorf("candidates of ambiguous organism missing: %q", lines[1])
+	}
+}
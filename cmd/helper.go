@@ -21,10 +21,18 @@
 package cmd
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/client"
 )
 
 func checkError(err error) {
@@ -43,3 +51,92 @@ func getDbFilePath(cmd *cobra.Command) (string, string, string) {
 	dataFilePath := filepath.Join(dbPath, dbFile)
 	return dataFilePath, dbPath, dbFile
 }
+
+// baseURL returns server URL of host and port,
+// host could be with or without scheme like "http://" or "https://"
+func baseURL(host string, port int) string {
+	host = strings.TrimSpace(host)
+	if strings.Contains(host, "://") {
+		return fmt.Sprintf("%s:%d", host, port)
+	}
+	return fmt.Sprintf("http://%s:%d", host, port)
+}
+
+// getServerURLs returns base URLs of servers from flag --url
+// (comma-separated), or --host and --port if --url not given
+func getServerURLs(cmd *cobra.Command) []string {
+	urls, err := cmd.Flags().GetString("url")
+	checkError(err)
+	if urls == "" {
+		host, err := cmd.Flags().GetString("host")
+		checkError(err)
+		port, err := cmd.Flags().GetInt("port")
+		checkError(err)
+		return []string{baseURL(host, port)}
+	}
+
+	servers := []string{}
+	for _, serverURL := range strings.Split(urls, ",") {
+		serverURL = strings.TrimSpace(serverURL)
+		if serverURL == "" {
+			continue
+		}
+		u, err := url.Parse(serverURL)
+		checkError(err)
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			checkError(fmt.Errorf("invalid server URL: %s, e.g., https://example.org:8443/gtaxon", serverURL))
+		}
+		servers = append(servers, strings.TrimRight(serverURL, "/"))
+	}
+	return servers
+}
+
+// getRetryOptions returns options of retrying failed queries from flags
+func getRetryOptions(cmd *cobra.Command) client.RetryOptions {
+	retries, err := cmd.Flags().GetInt("retries")
+	checkError(err)
+	backoff, err := cmd.Flags().GetDuration("retry-backoff")
+	checkError(err)
+	maxBackoff, err := cmd.Flags().GetDuration("retry-max-backoff")
+	checkError(err)
+	roundRobin, err := cmd.Flags().GetBool("round-robin")
+	checkError(err)
+	return client.RetryOptions{
+		Retries:    retries,
+		Backoff:    backoff,
+		MaxBackoff: maxBackoff,
+		RoundRobin: roundRobin,
+	}
+}
+
+// getClientTLSConfig returns TLS config from flags --ca-file, --cert
+// and --key, nil if none of them given
+func getClientTLSConfig(cmd *cobra.Command) *tls.Config {
+	caFile, err := cmd.Flags().GetString("ca-file")
+	checkError(err)
+	certFile, err := cmd.Flags().GetString("cert")
+	checkError(err)
+	keyFile, err := cmd.Flags().GetString("key")
+	checkError(err)
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return nil
+	}
+	cfg, err := taxon.NewClientTLSConfig(caFile, certFile, keyFile)
+	checkError(err)
+	return cfg
+}
+
+// addServerFlags adds flags of server URL and TLS
+func addServerFlags(cmd *cobra.Command, note string) {
+	cmd.Flags().StringP("host", "H", "127.0.0.1", "server host"+note)
+	cmd.Flags().IntP("port", "P", 8080, "port number"+note)
+	cmd.Flags().StringP("url", "U", "", `full base URL of server, e.g. "https://example.org:8443/gtaxon", overrides --host and --port. Multiple comma-separated servers are tried in turn if one fails`+note)
+	cmd.Flags().StringP("ca-file", "", "", "CA bundle (PEM) verifying server certificate, system CAs are used if not given"+note)
+	cmd.Flags().StringP("cert", "", "", "client certificate (PEM) for server requiring mutual TLS"+note)
+	cmd.Flags().StringP("key", "", "", "client key (PEM) for server requiring mutual TLS"+note)
+	cmd.Flags().StringP("api-key", "k", "", "API key, needed if server requires"+note)
+	cmd.Flags().IntP("retries", "", 3, "max number of retries of a query after all servers failed, only network errors, server errors and rate limiting are retried"+note)
+	cmd.Flags().DurationP("retry-backoff", "", time.Second, "time waiting before the first retry, doubled for every following retry"+note)
+	cmd.Flags().DurationP("retry-max-backoff", "", 30*time.Second, "max time waiting before a retry"+note)
+	cmd.Flags().BoolP("round-robin", "", false, "send queries to servers of --url in turn, instead of always starting from the first one"+note)
+}
@@ -41,6 +41,8 @@ Supported file types includes:
   ------------------------------------------------
     gi_taxid_nucl          gi_taxid_nucl.dmp.gz
     gi_taxid_prot          gi_taxid_prot.dmp.gz
+    acc_taxid_nucl         nucl_gb.accession2taxid.gz
+    acc_taxid_prot         prot.accession2taxid.gz
     nodes                  nodes.dmp
     names                  names.dmp
     divisions              division.dmp
@@ -72,32 +74,42 @@ Supported file types includes:
 		case "gi_taxid_nucl":
 			log.Info("Import from file: %s", dataFile)
 
-			taxon.ImportGiTaxid(dbFilePath, "gi_taxid_nucl", dataFile, chunkSize, force)
+			checkError(taxon.ImportGiTaxid(dbFilePath, "gi_taxid_nucl", dataFile, chunkSize, force))
 
 		case "gi_taxid_prot":
 			log.Info("Import from file: %s", dataFile)
 
-			taxon.ImportGiTaxid(dbFilePath, "gi_taxid_prot", dataFile, chunkSize, force)
+			checkError(taxon.ImportGiTaxid(dbFilePath, "gi_taxid_prot", dataFile, chunkSize, force))
+
+		case "acc_taxid_nucl":
+			log.Infof("Import from file: %s", dataFile)
+
+			checkError(taxon.ImportAccTaxid(dbFilePath, "acc_taxid_nucl", dataFile, chunkSize, force))
+
+		case "acc_taxid_prot":
+			log.Infof("Import from file: %s", dataFile)
+
+			checkError(taxon.ImportAccTaxid(dbFilePath, "acc_taxid_prot", dataFile, chunkSize, force))
 
 		case "nodes":
 			log.Info("Import from file: %s", dataFile)
 
-			taxon.ImportNodes(dbFilePath, "nodes", dataFile, chunkSize, force)
+			checkError(taxon.ImportNodes(dbFilePath, "nodes", dataFile, chunkSize, force))
 
 		case "names":
 			log.Info("Import from file: %s", dataFile)
 
-			taxon.ImportNames(dbFilePath, "names", dataFile, chunkSize, force)
+			checkError(taxon.ImportNames(dbFilePath, "names", dataFile, chunkSize, force))
 
 		case "divisions":
 			log.Info("Import from file: %s", dataFile)
 
-			taxon.ImportDivisions(dbFilePath, "divisions", dataFile, chunkSize, force)
+			checkError(taxon.ImportDivisions(dbFilePath, "divisions", dataFile, chunkSize, force))
 
 		case "gencodes":
 			log.Info("Import from file: %s", dataFile)
 
-			taxon.ImportGenCodes(dbFilePath, "gencodes", dataFile, chunkSize, force)
+			checkError(taxon.ImportGenCodes(dbFilePath, "gencodes", dataFile, chunkSize, force))
 
 		default:
 			log.Errorf("Unsupported filetype: %s", fileType)
@@ -39,7 +39,7 @@ var initCmd = &cobra.Command{
 		force, err := cmd.Flags().GetBool("force")
 		checkError(err)
 
-		taxon.InitDatabase(dbPath, dbFile, force)
+		checkError(taxon.InitDatabase(dbPath, dbFile, force))
 	},
 }
 
@@ -21,15 +21,10 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 	"runtime"
-	"strings"
-	"sync"
 
-	"github.com/boltdb/bolt"
-	"github.com/shenwei356/breader"
-	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/client"
 	"github.com/spf13/cobra"
 )
 
@@ -43,6 +38,19 @@ Available query types:
 
     gi_taxid_nucl      query TaxId by Gi (nucl)
     gi_taxid_prot      query TaxId by Gi (prot)
+    acc_taxid_nucl     query TaxId by accession (nucl)
+    acc_taxid_prot     query TaxId by accession (prot)
+
+    taxid2taxon        query Taxon by TaxId
+    name2taxid         query TaxId by Name
+    lca                query Lowest Common Ancestor by TaxIds
+
+Names, nodes, divisions and gencodes are loaded into memory
+for the last three query types, which takes a while.
+
+Results are printed in the order of queries, one for every query
+(or line of file, including duplicates and blank lines), and
+"NA" for queries not found.
 
 `,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -68,115 +76,49 @@ Available query types:
 			}
 		}
 
-		dbFilePath, _, _ := getDbFilePath(cmd)
-
 		switch queryType {
 		case "":
 			log.Error("Flag -t/--type needed")
-
-		case "gi_taxid_nucl":
-			log.Info("Query database: %s", "gi_taxid_nucl")
-
-			if dataFile == "" {
-				queryGi2Taxid(dbFilePath, "gi_taxid_nucl", args)
-			} else {
-				queryGi2TaxidByFile(dbFilePath, "gi_taxid_nucl", dataFile, chunkSize, threads)
-			}
-
-		case "gi_taxid_prot":
-			log.Info("Query database: %s", "gi_taxid_prot")
-
-			if dataFile == "" {
-				queryGi2Taxid(dbFilePath, "gi_taxid_prot", args)
-			} else {
-				queryGi2TaxidByFile(dbFilePath, "gi_taxid_prot", dataFile, chunkSize, threads)
-			}
+			return
+		case "gi_taxid_nucl", "gi_taxid_prot", "acc_taxid_nucl", "acc_taxid_prot":
+			log.Infof("Query database: %s", queryType)
+		case "taxid2taxon":
+			log.Info("Query Taxon by TaxId")
+		case "name2taxid":
+			log.Info("Query TaxId by Name")
+		case "lca":
+			log.Info("Query LCA by TaxIds")
 		default:
 			log.Errorf("Unsupported data type: %s", queryType)
 			os.Exit(-1)
 		}
-	},
-}
-
-func queryGi2Taxid(dbFilePath string, queryType string, gis []string) {
-	db, err := bolt.Open(dbFilePath, 0600, nil)
-	defer db.Close()
-	checkError(err)
 
-	taxids, err := taxon.QueryGi2Taxid(db, queryType, gis)
-	checkError(err)
-
-	for i, gi := range gis {
-		fmt.Printf("%s\t%s\n", gi, taxids[i])
-	}
-}
-
-func queryGi2TaxidByFile(dbFilePath string, queryType string, dataFile string, chunkSize int, threads int) {
-	if chunkSize <= 0 {
-		chunkSize = 10000
-	}
-	fn := func(line string) (interface{}, bool, error) {
-		line = strings.TrimSpace(strings.TrimRight(line, "\n"))
-		if line == "" {
-			return "", false, nil
-		}
-		return line, true, nil
-	}
-	reader, err := breader.NewBufferedReader(dataFile, runtime.NumCPU(), chunkSize, fn)
-	checkError(err)
-
-	pool := taxon.NewDBPool(dbFilePath, threads)
-	chResults := make(chan [][]string, threads)
-
-	// receive result and print
-	chDone := make(chan int)
-	go func() {
-		for s := range chResults {
-			gis, taxids := s[0], s[1]
-			for i, gi := range gis {
-				fmt.Printf("%s\t%s\n", gi, taxids[i])
-			}
-		}
-		chDone <- 1
-	}()
-
-	// querying
-	var wg sync.WaitGroup
-	tokens := make(chan int, threads)
-	for chunk := range reader.Ch {
-		if chunk.Err != nil {
-			checkError(chunk.Err)
-			break
-		}
-		tokens <- 1
-		wg.Add(1)
+		nameClass, err := cmd.Flags().GetString("name-class")
+		checkError(err)
+		useRegexp, err := cmd.Flags().GetBool("use-regexp")
+		checkError(err)
 
-		gis := make([]string, len(chunk.Data))
-		for i, data := range chunk.Data {
-			gis[i] = data.(string)
-		}
+		dbFilePath, _, _ := getDbFilePath(cmd)
+		q, err := client.NewLocal(dbFilePath, threads)
+		checkError(err)
+		defer q.Close()
 
-		go func(gis []string) {
-			db := pool.GetDB()
-			defer func() {
-				pool.ReleaseDB(db)
-				wg.Done()
-				<-tokens
-			}()
-
-			taxids, err := taxon.QueryGi2Taxid(db, queryType, gis)
-			checkError(err)
-			chResults <- [][]string{gis, taxids}
-		}(gis)
-	}
-	wg.Wait()
-	close(chResults)
-	<-chDone
+		in := getInputOptions(cmd)
+		out := newOutputter(cmd, queryType, in)
+		query := newQueryFunc(q, queryType, useRegexp, nameClass, out.needTaxons)
+		err = runQueries(query, out.print, args, dataFile, in, chunkSize, threads)
+		out.close()
+		checkError(err)
+	},
 }
 
 func init() {
 	cliCmd.AddCommand(localCmd)
 	localCmd.Flags().StringP("type", "t", "", "query type (see introduction)")
-	localCmd.Flags().StringP("file", "f", "", "read queries from file")
+	localCmd.Flags().StringP("file", "f", "", `read queries from file, "-" for stdin`)
 	localCmd.Flags().IntP("chunk-size", "c", 100000, "chunk size of querying")
+	localCmd.Flags().BoolP("use-regexp", "R", false, `use regexp (only for query type "name2taxid")`)
+	localCmd.Flags().StringP("name-class", "C", "", `name class (only for query type "name2taxid")`)
+	addInputFlags(localCmd)
+	addOutputFlags(localCmd)
 }
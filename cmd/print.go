@@ -0,0 +1,369 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+	"github.com/spf13/cobra"
+)
+
+// notFound is printed for queries not found
+const notFound = "NA"
+
+// columnValues returns values of columns of TSV and CSV output from Taxon
+var columnValues = map[string]func(t nodes.Taxon) string{
+	"taxid":    func(t nodes.Taxon) string { return strconv.Itoa(t.TaxId) },
+	"name":     func(t nodes.Taxon) string { return t.ScientificName },
+	"rank":     func(t nodes.Taxon) string { return t.Rank },
+	"parent":   func(t nodes.Taxon) string { return strconv.Itoa(t.ParentTaxId) },
+	"division": func(t nodes.Taxon) string { return t.Division },
+	"lineage":  func(t nodes.Taxon) string { return t.Lineage },
+	"lineage_taxids": func(t nodes.Taxon) string {
+		taxids := make([]string, len(t.LineageEx))
+		for i, item := range t.LineageEx {
+			taxids[i] = strconv.Itoa(item.TaxId)
+		}
+		return strings.Join(taxids, ";")
+	},
+	"genetic_code":      func(t nodes.Taxon) string { return strconv.Itoa(t.GeneticCode.GCId) },
+	"mito_genetic_code": func(t nodes.Taxon) string { return strconv.Itoa(t.MitoGeneticCode.MGCId) },
+}
+
+// columnNames are names of columns in the order of help message
+var columnNames = []string{"query", "found", "taxid", "name", "rank", "parent", "division",
+	"lineage", "lineage_taxids", "genetic_code", "mito_genetic_code"}
+
+// defaultColumns returns default columns of TSV and CSV output of query type
+func defaultColumns(queryType string) []string {
+	switch queryType {
+	case "taxid2taxon", "lca":
+		return []string{"query", "taxid", "name", "rank", "lineage"}
+	case "name2taxid":
+		return []string{"query", "taxid", "name"}
+	}
+	return []string{"query", "taxid"}
+}
+
+// taxons returns Taxons of a found record. Only TaxIds are available for
+// GIs, and TaxIds and scientific names for names, if Taxons not resolved.
+func (r record) taxons() []nodes.Taxon {
+	switch {
+	case r.TaxIDs != nil:
+		if r.Taxons != nil {
+			return r.Taxons
+		}
+		taxons := make([]nodes.Taxon, len(r.TaxIDs))
+		for i, item := range r.TaxIDs {
+			taxons[i] = nodes.Taxon{TaxId: item.TaxID, ScientificName: item.ScientificName}
+		}
+		return taxons
+	case r.TaxID != "" && r.Taxon.TaxId == 0:
+		taxid, _ := strconv.Atoi(r.TaxID)
+		return []nodes.Taxon{{TaxId: taxid}}
+	}
+	return []nodes.Taxon{r.Taxon}
+}
+
+// outputter prints records in format of --out-format
+type outputter struct {
+	format    string
+	queryType string
+	columns   []string
+	header    bool
+	tmpl      *template.Template
+	in        inputOptions // input columns are passed through with --field
+
+	// needTaxons tells whether Taxons of GIs and names are needed
+	needTaxons bool
+
+	w      *bufio.Writer
+	csvw   *csv.Writer
+	n      int    // number of records printed
+	record record // record being printed by template
+}
+
+// newOutputter creates outputter of query type from flags. With --field,
+// results are appended to input lines, and TSV is used for format "text".
+func newOutputter(cmd *cobra.Command, queryType string, in inputOptions) *outputter {
+	format, err := cmd.Flags().GetString("out-format")
+	checkError(err)
+	columnsStr, err := cmd.Flags().GetString("columns")
+	checkError(err)
+	noHeader, err := cmd.Flags().GetBool("no-header")
+	checkError(err)
+	tmplStr, err := cmd.Flags().GetString("template")
+	checkError(err)
+
+	o := &outputter{
+		format:    format,
+		queryType: queryType,
+		header:    !noHeader,
+		in:        in,
+		w:         bufio.NewWriter(os.Stdout),
+	}
+	if in.field > 0 {
+		if format == "text" {
+			format, o.format = "tsv", "tsv"
+		}
+		o.header = false
+	}
+
+	switch format {
+	case "text", "json", "jsonl":
+	case "tsv", "csv":
+		if columnsStr == "" {
+			o.columns = defaultColumns(queryType)
+			if in.field > 0 { // query is already in input line
+				o.columns = o.columns[1:]
+			}
+		} else {
+			o.columns = strings.Split(columnsStr, ",")
+		}
+		for _, column := range o.columns {
+			switch column {
+			case "query", "found":
+			case "taxid":
+			case "name":
+				o.needTaxons = o.needTaxons || queryType != "name2taxid"
+			default:
+				if _, ok := columnValues[column]; !ok {
+					checkError(fmt.Errorf("invalid column: %s, available: %s", column, strings.Join(columnNames, ", ")))
+				}
+				o.needTaxons = true
+			}
+		}
+		if format == "csv" {
+			o.csvw = csv.NewWriter(o.w)
+		}
+	case "template":
+		if tmplStr == "" {
+			checkError(fmt.Errorf("flag --template needed for output format: template"))
+		}
+		tmplStr = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(tmplStr)
+		o.tmpl, err = template.New("taxon").Funcs(template.FuncMap{
+			"query": func() string { return o.record.Query },
+			"found": func() bool { return o.record.Found },
+		}).Parse(tmplStr)
+		checkError(err)
+		o.needTaxons = true
+	default:
+		checkError(fmt.Errorf("invalid output format: %s, available: text, tsv, csv, json, jsonl, template", format))
+	}
+	return o
+}
+
+// print prints a record
+func (o *outputter) print(r record) {
+	var err error
+	if r.PassThrough {
+		if o.format != "json" && o.format != "jsonl" {
+			_, err = o.w.WriteString(r.Line + "\n")
+			checkError(err)
+		}
+		return
+	}
+	switch o.format {
+	case "text":
+		printText(o.w, o.queryType, r)
+	case "tsv", "csv":
+		if o.n == 0 && o.header {
+			o.writeRow(o.columns)
+		}
+		row := make([]string, len(o.columns))
+		for i, column := range o.columns {
+			row[i] = o.columnValue(r, column)
+		}
+		switch {
+		case o.in.field == 0:
+		case o.csvw != nil:
+			row = append(append([]string{}, r.Fields...), row...)
+		default:
+			row = append([]string{r.Line}, row...)
+		}
+		o.writeRow(row)
+	case "json", "jsonl":
+		var v interface{} = o.v1Result(r)
+		if o.in.field > 0 {
+			v = fieldsResult{Fields: r.Fields, Result: v}
+		}
+		var bs []byte
+		if o.format == "jsonl" {
+			bs, err = json.Marshal(v)
+			checkError(err)
+			bs = append(bs, '\n')
+		} else {
+			bs, err = json.MarshalIndent(v, "  ", "  ")
+			checkError(err)
+			if o.n == 0 {
+				bs = append([]byte("[\n  "), bs...)
+			} else {
+				bs = append([]byte(",\n  "), bs...)
+			}
+		}
+		_, err = o.w.Write(bs)
+	case "template":
+		o.record = r
+		if o.in.field > 0 {
+			o.w.WriteString(r.Line + o.in.delimiter)
+		}
+		taxons := []nodes.Taxon{{}}
+		if r.Found {
+			taxons = r.taxons()
+		}
+		for i, t := range taxons {
+			if i > 0 {
+				o.w.WriteString(",")
+			}
+			checkError(o.tmpl.Execute(o.w, t))
+		}
+		_, err = o.w.WriteString("\n")
+	}
+	checkError(err)
+	o.n++
+}
+
+// close finishes output
+func (o *outputter) close() {
+	switch o.format {
+	case "tsv", "csv":
+		if o.n == 0 && o.header {
+			o.writeRow(o.columns)
+		}
+		if o.csvw != nil {
+			o.csvw.Flush()
+			checkError(o.csvw.Error())
+		}
+	case "json":
+		if o.n == 0 {
+			o.w.WriteString("[")
+		}
+		o.w.WriteString("\n]\n")
+	}
+	checkError(o.w.Flush())
+}
+
+func (o *outputter) writeRow(row []string) {
+	if o.csvw != nil {
+		checkError(o.csvw.Write(row))
+		return
+	}
+	sep := "\t"
+	if o.in.field > 0 {
+		sep = o.in.delimiter
+	}
+	_, err := o.w.WriteString(strings.Join(row, sep) + "\n")
+	checkError(err)
+}
+
+// columnValue returns value of column of record, values of
+// multiple matched taxids of a name are joined by ","
+func (o *outputter) columnValue(r record, column string) string {
+	switch column {
+	case "query":
+		return r.Query
+	case "found":
+		return strconv.FormatBool(r.Found)
+	}
+	if !r.Found {
+		return notFound
+	}
+	if column == "taxid" && r.TaxID != "" {
+		return r.TaxID
+	}
+	taxons := r.taxons()
+	values := make([]string, len(taxons))
+	for i, t := range taxons {
+		values[i] = columnValues[column](t)
+	}
+	return strings.Join(values, ",")
+}
+
+// fieldsResult is JSON output with --field
+type fieldsResult struct {
+	Fields []string    `json:"fields"` // columns of input line
+	Result interface{} `json:"result"`
+}
+
+// v1Result returns result of record in the same structure of REST API v1
+func (o *outputter) v1Result(r record) interface{} {
+	switch o.queryType {
+	case "taxid2taxon", "lca":
+		result := taxon.V1TaxonResult{Query: r.Query, Found: r.Found}
+		if r.Found {
+			result.Taxon = taxon.NewV1Taxon(r.Taxon)
+		}
+		return result
+	case "name2taxid":
+		return taxon.V1Name2TaxIDResult{Query: r.Query, Found: r.Found, TaxIDs: taxon.NewV1TaxIDNames(r.TaxIDs)}
+	}
+	taxid, _ := strconv.Atoi(r.TaxID)
+	return taxon.V1TaxIDResult{Query: r.Query, Found: r.Found, TaxID: taxid}
+}
+
+// printText prints record in human readable format:
+// "query\ttaxid" for GIs, "name\ttaxid(scientific name),..." for names,
+// and query followed by indented JSON of Taxon for taxids and LCA.
+func printText(w *bufio.Writer, queryType string, r record) {
+	switch queryType {
+	case "taxid2taxon", "lca":
+		fmt.Fprintf(w, "Query TaxIDs: %s\n", r.Query)
+		if !r.Found {
+			fmt.Fprintf(w, "Taxon: %s\n\n", notFound)
+			return
+		}
+		bs, err := json.MarshalIndent(r.Taxon, "", "  ")
+		checkError(err)
+		fmt.Fprintf(w, "Taxon: %s\n\n", string(bs))
+	case "name2taxid":
+		if !r.Found {
+			fmt.Fprintf(w, "%s\t%s\n", r.Query, notFound)
+			return
+		}
+		idnames := make([]string, len(r.TaxIDs))
+		for i, item := range r.TaxIDs {
+			idnames[i] = fmt.Sprintf("%d(%s)", item.TaxID, item.ScientificName)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", r.Query, strings.Join(idnames, ","))
+	default:
+		taxid := r.TaxID
+		if !r.Found {
+			taxid = notFound
+		}
+		fmt.Fprintf(w, "%s\t%s\n", r.Query, taxid)
+	}
+}
+
+// addOutputFlags adds flags of output format
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("out-format", "o", "text", `output format: "text" (human readable), "tsv", "csv", "json", "jsonl" or "template"`)
+	cmd.Flags().StringP("columns", "", "", `comma-separated columns of TSV/CSV output, available: `+strings.Join(columnNames, ", ")+`. default: "query,taxid" for GIs, "query,taxid,name" for names, and "query,taxid,name,rank,lineage" for others`)
+	cmd.Flags().BoolP("no-header", "", false, "do not print header line of TSV/CSV output")
+	cmd.Flags().StringP("template", "", "", `Go text/template over nodes.Taxon for output format "template", e.g. '{{query}}\t{{.TaxId}}\t{{.Rank}}\t{{.Lineage}}'. functions "query" and "found" return query and whether it's found`)
+}
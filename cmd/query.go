@@ -0,0 +1,649 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/shenwei356/breader"
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/client"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+	"github.com/spf13/cobra"
+)
+
+// record is result of one query line. Found is false for queries
+// not found and blank lines, which are not queried.
+type record struct {
+	Query  string
+	Found  bool
+	TaxID  string                   // gi_taxid_*, acc_taxid_*
+	Taxon  nodes.Taxon              // taxid2taxon, lca
+	TaxIDs []taxon.TaxIDSciNameItem // name2taxid
+	Taxons []nodes.Taxon            // name2taxid, Taxons of TaxIDs if needed
+
+	// with --field
+	Line        string   // input line
+	Fields      []string // columns of input line
+	PassThrough bool     // comment or blank line, printed as it is
+}
+
+// queryFunc queries a chunk of queries, records are in the order of queries.
+// If emit is not nil, records may also be passed to emit as they arrive,
+// with index of the query, e.g., from NDJSON responses of server.
+type queryFunc func(queries []string, emit func(i int, r record)) ([]record, error)
+
+// streamer returns q as client.Streamer if records are to be emitted
+func streamer(q client.Querier, emit func(i int, r record)) (client.Streamer, bool) {
+	if emit == nil {
+		return nil, false
+	}
+	s, ok := q.(client.Streamer)
+	return s, ok
+}
+
+// newQueryFunc returns queryFunc of query type, querying by q.
+// Taxons of TaxIds of GIs and names are also queried if needTaxons.
+func newQueryFunc(q client.Querier, queryType string, useRegexp bool, nameClass string, needTaxons bool) queryFunc {
+	ctx := context.Background()
+	switch queryType {
+	case "gi_taxid_nucl", "gi_taxid_prot", "acc_taxid_nucl", "acc_taxid_prot":
+		return func(queries []string, emit func(i int, r record)) ([]record, error) {
+			if s, ok := streamer(q, emit); ok && !needTaxons {
+				records := make([]record, len(queries))
+				err := s.StreamGi2TaxID(ctx, queryType, queries, func(i int, taxid string) {
+					records[i] = record{Query: queries[i], Found: taxid != "", TaxID: taxid}
+					emit(i, records[i])
+				})
+				if err != nil {
+					return nil, err
+				}
+				return records, nil
+			}
+			taxids, err := q.Gi2TaxID(ctx, queryType, queries)
+			if err != nil {
+				return nil, err
+			}
+			var taxons map[string]nodes.Taxon
+			if needTaxons {
+				if taxons, err = taxonsOf(ctx, q, taxids); err != nil {
+					return nil, err
+				}
+			}
+			records := make([]record, len(queries))
+			for i, query := range queries {
+				records[i] = record{Query: query, Found: taxids[i] != "", TaxID: taxids[i], Taxon: taxons[taxids[i]]}
+			}
+			return records, nil
+		}
+	case "taxid2taxon":
+		return func(queries []string, emit func(i int, r record)) ([]record, error) {
+			if s, ok := streamer(q, emit); ok {
+				records := make([]record, len(queries))
+				err := s.StreamTaxID2Taxon(ctx, queries, func(i int, t nodes.Taxon) {
+					records[i] = record{Query: queries[i], Found: t.TaxId > 0, Taxon: t}
+					emit(i, records[i])
+				})
+				if err != nil {
+					return nil, err
+				}
+				return records, nil
+			}
+			taxons, err := q.TaxID2Taxon(ctx, queries)
+			if err != nil {
+				return nil, err
+			}
+			records := make([]record, len(queries))
+			for i, query := range queries {
+				records[i] = record{Query: query, Found: taxons[i].TaxId > 0, Taxon: taxons[i]}
+			}
+			return records, nil
+		}
+	case "name2taxid":
+		return func(queries []string, emit func(i int, r record)) ([]record, error) {
+			if s, ok := streamer(q, emit); ok && !needTaxons {
+				records := make([]record, len(queries))
+				err := s.StreamName2TaxID(ctx, useRegexp, nameClass, queries, func(i int, taxids []taxon.TaxIDSciNameItem) {
+					records[i] = record{Query: queries[i], Found: len(taxids) > 0, TaxIDs: taxids}
+					emit(i, records[i])
+				})
+				if err != nil {
+					return nil, err
+				}
+				return records, nil
+			}
+			results, err := q.Name2TaxID(ctx, useRegexp, nameClass, queries)
+			if err != nil {
+				return nil, err
+			}
+			var taxons map[string]nodes.Taxon
+			if needTaxons {
+				taxids := []string{}
+				for _, items := range results {
+					for _, item := range items {
+						taxids = append(taxids, strconv.Itoa(item.TaxID))
+					}
+				}
+				if taxons, err = taxonsOf(ctx, q, taxids); err != nil {
+					return nil, err
+				}
+			}
+			records := make([]record, len(queries))
+			for i, query := range queries {
+				records[i] = record{Query: query, Found: len(results[i]) > 0, TaxIDs: results[i]}
+				if needTaxons {
+					records[i].Taxons = make([]nodes.Taxon, len(results[i]))
+					for j, item := range results[i] {
+						records[i].Taxons[j] = taxons[strconv.Itoa(item.TaxID)]
+					}
+				}
+			}
+			return records, nil
+		}
+	case "lca":
+		return func(queries []string, emit func(i int, r record)) ([]record, error) {
+			groups := make([][]string, len(queries))
+			for i, query := range queries {
+				groups[i] = strings.Split(query, ",")
+			}
+			if s, ok := streamer(q, emit); ok {
+				records := make([]record, len(queries))
+				err := s.StreamLCA(ctx, groups, func(i int, t nodes.Taxon) {
+					records[i] = record{Query: queries[i], Found: t.TaxId > 0, Taxon: t}
+					emit(i, records[i])
+				})
+				if err != nil {
+					return nil, err
+				}
+				return records, nil
+			}
+			lcas, err := q.LCA(ctx, groups)
+			if err != nil {
+				return nil, err
+			}
+			records := make([]record, len(queries))
+			for i, query := range queries {
+				records[i] = record{Query: query, Found: lcas[i].TaxId > 0, Taxon: lcas[i]}
+			}
+			return records, nil
+		}
+	}
+	log.Errorf("Unsupported data type: %s", queryType)
+	os.Exit(-1)
+	return nil
+}
+
+// taxonsOf queries Taxons of unique non-empty taxids
+func taxonsOf(ctx context.Context, q client.Querier, taxids []string) (map[string]nodes.Taxon, error) {
+	unique := make([]string, 0, len(taxids))
+	taxons := make(map[string]nodes.Taxon, len(taxids))
+	for _, taxid := range taxids {
+		if taxid == "" {
+			continue
+		}
+		if _, ok := taxons[taxid]; ok {
+			continue
+		}
+		taxons[taxid] = nodes.Taxon{}
+		unique = append(unique, taxid)
+	}
+	if len(unique) == 0 {
+		return taxons, nil
+	}
+	results, err := q.TaxID2Taxon(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	for i, taxid := range unique {
+		taxons[taxid] = results[i]
+	}
+	return taxons, nil
+}
+
+// inputOptions are options of reading queries from file
+type inputOptions struct {
+	field     int    // column number of query (1-based), 0 for the whole line
+	delimiter string // delimiter of columns
+
+	keepGoing  bool   // continue past failed chunks
+	rejectFile string // file saving lines of failed chunks
+}
+
+// getInputOptions returns inputOptions from flags
+func getInputOptions(cmd *cobra.Command) inputOptions {
+	field, err := cmd.Flags().GetInt("field")
+	checkError(err)
+	delimiter, err := cmd.Flags().GetString("delimiter")
+	checkError(err)
+	if field < 0 {
+		checkError(fmt.Errorf("value of --field should be positive: %d", field))
+	}
+	if delimiter == "" {
+		checkError(fmt.Errorf("value of --delimiter should not be empty"))
+	}
+	keepGoing, err := cmd.Flags().GetBool("keep-going")
+	checkError(err)
+	rejectFile, err := cmd.Flags().GetString("reject-file")
+	checkError(err)
+	return inputOptions{
+		field:      field,
+		delimiter:  strings.NewReplacer(`\t`, "\t").Replace(delimiter),
+		keepGoing:  keepGoing || rejectFile != "",
+		rejectFile: rejectFile,
+	}
+}
+
+// addInputFlags adds flags of reading query file
+func addInputFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("field", "", 0, `column number of query (1-based) of file of -f, results are appended to lines. 0 for the whole line`)
+	cmd.Flags().StringP("delimiter", "", "\t", "column delimiter of file of -f, for --field")
+	cmd.Flags().BoolP("keep-going", "", false, "continue past failed chunks of file of -f, which are missing in output")
+	cmd.Flags().StringP("reject-file", "", "", "write lines of failed chunks to this file for querying again, implies --keep-going")
+}
+
+// parse parses a line of query file. With --field, comment lines
+// starting with "#" and blank lines are passed through.
+func (in inputOptions) parse(line string) record {
+	if in.field == 0 {
+		return record{Query: strings.TrimSpace(line)}
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" || line[0] == '#' {
+		return record{Line: line, PassThrough: true}
+	}
+	r := record{Line: line, Fields: strings.Split(line, in.delimiter)}
+	if in.field <= len(r.Fields) {
+		r.Query = strings.TrimSpace(r.Fields[in.field-1])
+	}
+	return r
+}
+
+// queryLines queries records of lines with non-empty queries.
+// Records of others are kept (not found or passed through),
+// so that there's one record for every line. If emit is not nil,
+// records are also passed to emit with index of the line,
+// records of lines not queried are emitted first.
+func queryLines(query queryFunc, lines []record, emit func(i int, r record)) ([]record, error) {
+	queries := make([]string, 0, len(lines))
+	index := make([]int, 0, len(lines)) // line index of queries
+	for i, line := range lines {
+		if line.Query != "" {
+			queries = append(queries, line.Query)
+			index = append(index, i)
+		} else if emit != nil {
+			emit(i, line)
+		}
+	}
+	records := lines
+	if len(queries) == 0 {
+		return records, nil
+	}
+	var emitLine func(i int, r record)
+	if emit != nil {
+		emitLine = func(i int, r record) {
+			line := lines[index[i]]
+			r.Line, r.Fields = line.Line, line.Fields
+			emit(index[i], r)
+		}
+	}
+	results, size, err := queryValid(query, queries, emitLine)
+	if err != nil {
+		return nil, err
+	}
+	if size > 0 {
+		splitWarning.Do(func() {
+			log.Warningf("%d queries are too many for one request of server, split into batches of %d. please lower --chunk-size to %d", len(queries), size, size)
+		})
+	}
+	j := 0
+	for i, line := range lines {
+		if line.Query == "" {
+			continue
+		}
+		records[i] = results[j]
+		records[i].Line, records[i].Fields = line.Line, line.Fields
+		j++
+	}
+	return records, nil
+}
+
+// splitWarning warns once that chunks are split for max batch size of server
+var splitWarning sync.Once
+
+// querySplitting queries, and splits queries into halves recursively
+// when exceeding the max batch size of server. It also returns size
+// of the largest batch succeeded if split, 0 otherwise.
+func querySplitting(query queryFunc, queries []string, emit func(i int, r record)) ([]record, int, error) {
+	results, err := query(queries, emit)
+	if err == nil {
+		return results, 0, nil
+	}
+	if !errors.Is(err, taxon.ErrBatchTooLarge) || len(queries) == 1 {
+		return nil, 0, err
+	}
+	half := len(queries) / 2
+	results, size, err := querySplitting(query, queries[:half], emit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if size == 0 {
+		size = half
+	}
+	results2, size2, err := querySplitting(query, queries[half:], emitAt(emit, func(i int) int { return half + i }))
+	if err != nil {
+		return nil, 0, err
+	}
+	if size2 == 0 {
+		size2 = len(queries) - half
+	}
+	if size2 > size {
+		size = size2
+	}
+	return append(results, results2...), size, nil
+}
+
+// queryValid queries, and on errors of malformed queries, reports the
+// offending queries as not found and queries the others again in one request.
+// The error is returned if it does not tell which queries are malformed.
+func queryValid(query queryFunc, queries []string, emit func(i int, r record)) ([]record, int, error) {
+	results, size, err := querySplitting(query, queries, emit)
+	if !errors.Is(err, taxon.ErrInvalidQuery) {
+		return results, size, err
+	}
+	offenders := offendingQueries(err)
+	rest := make([]string, 0, len(queries))
+	index := make([]int, 0, len(queries)) // index of rest in queries
+	for i, q := range queries {
+		if !isOffending(q, offenders) {
+			rest = append(rest, q)
+			index = append(index, i)
+		}
+	}
+	if len(rest) == len(queries) {
+		return nil, 0, err
+	}
+	log.Warning(err)
+
+	if emit != nil {
+		for i, q := range queries {
+			if isOffending(q, offenders) {
+				emit(i, record{Query: q})
+			}
+		}
+	}
+	if len(rest) > 0 {
+		if results, size, err = queryValid(query, rest, emitAt(emit, func(i int) int { return index[i] })); err != nil {
+			return nil, 0, err
+		}
+	}
+	records := make([]record, len(queries))
+	j := 0
+	for i, q := range queries {
+		if isOffending(q, offenders) {
+			records[i] = record{Query: q}
+			continue
+		}
+		records[i] = results[j]
+		j++
+	}
+	return records, size, nil
+}
+
+// emitAt returns emit of records at index(i) for records at i,
+// nil if emit is nil
+func emitAt(emit func(i int, r record), index func(i int) int) func(i int, r record) {
+	if emit == nil {
+		return nil
+	}
+	return func(i int, r record) {
+		emit(index(i), r)
+	}
+}
+
+// offendingQueries returns offending query values carried by
+// errors of local or remote queries
+func offendingQueries(err error) []string {
+	var queryErr *taxon.QueryError
+	if errors.As(err, &queryErr) {
+		return queryErr.Queries
+	}
+	var apiErr *taxon.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Queries
+	}
+	var remoteErr *client.RemoteError
+	if errors.As(err, &remoteErr) {
+		return remoteErr.Queries
+	}
+	return nil
+}
+
+// isOffending tells whether query is one of offenders,
+// or contains one as an item of comma-separated taxids (lca)
+func isOffending(query string, offenders []string) bool {
+	for _, offender := range offenders {
+		if query == offender {
+			return true
+		}
+		for _, item := range strings.Split(query, ",") {
+			if item == offender {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runQueries queries args, or chunks of lines of dataFile ("-" for stdin)
+// concurrently, and prints records in the order of input lines.
+// Records are printed as they arrive if query emits them.
+// Failed chunks are reported, and querying stops at the first one
+// unless --keep-going or --reject-file given.
+func runQueries(query queryFunc, print func(record), args []string, dataFile string, in inputOptions, chunkSize int, threads int) error {
+	if dataFile == "" {
+		lines := make([]record, len(args))
+		for i, arg := range args {
+			lines[i] = record{Query: strings.TrimSpace(arg)}
+		}
+		records, err := queryLines(query, lines, nil)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			print(r)
+		}
+		return nil
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = 1000
+	}
+	fn := func(line string) (interface{}, bool, error) {
+		return in.parse(line), true, nil
+	}
+	reader, err := breader.NewBufferedReader(dataFile, threads, chunkSize, fn)
+	checkError(err)
+
+	var reject *bufio.Writer
+	if in.rejectFile != "" {
+		fh, err := os.Create(in.rejectFile)
+		checkError(err)
+		defer fh.Close()
+		reject = bufio.NewWriter(fh)
+		defer reject.Flush()
+	}
+
+	// chunkRecords is a record of a chunk emitted as it arrives,
+	// or all records of a chunk when done
+	type chunkRecords struct {
+		id uint64
+
+		index  int
+		record record
+
+		done    bool
+		records []record
+		err     error
+	}
+	// chunkState is state of printing a chunk
+	type chunkState struct {
+		arrived map[int]record // records arrived and not printed yet
+		printed int            // number of records printed
+		result  *chunkRecords  // result of done chunk
+	}
+	chResults := make(chan chunkRecords, threads)
+	var stop int32 // set when stopping at a failed chunk
+	var errStop error
+	var failedChunks, failedLines int
+	var next uint64
+
+	// receive results and print, records of a chunk are printed
+	// as they arrive if all records before them are printed,
+	// and others are buffered until then
+	chDone := make(chan int)
+	go func() {
+		chunks := make(map[uint64]*chunkState)
+		for result := range chResults {
+			state, ok := chunks[result.id]
+			if !ok {
+				state = &chunkState{arrived: make(map[int]record)}
+				chunks[result.id] = state
+			}
+			if result.done {
+				result := result
+				state.result = &result
+			} else if result.index >= state.printed {
+				state.arrived[result.index] = result.record
+			}
+
+			for {
+				state, ok := chunks[next]
+				if !ok {
+					break
+				}
+				if atomic.LoadInt32(&stop) == 0 {
+					for {
+						r, ok := state.arrived[state.printed]
+						if !ok {
+							break
+						}
+						print(r)
+						delete(state.arrived, state.printed)
+						state.printed++
+					}
+				}
+				if state.result == nil {
+					break
+				}
+				delete(chunks, next)
+				next++
+				if atomic.LoadInt32(&stop) == 1 {
+					continue
+				}
+
+				result := state.result
+				records := result.records[state.printed:]
+				if result.err == nil {
+					for _, r := range records {
+						print(r)
+					}
+					continue
+				}
+
+				failedChunks++
+				failedLines += len(records)
+				first := result.id*uint64(chunkSize) + uint64(state.printed) + 1
+				log.Errorf("chunk %d (lines %d-%d) failed: %s", result.id+1,
+					first, first+uint64(len(records))-1, result.err)
+				if !in.keepGoing {
+					errStop = fmt.Errorf("stopped at failed chunk %d, use --keep-going to continue past failed chunks", result.id+1)
+					atomic.StoreInt32(&stop, 1)
+					continue
+				}
+				if reject != nil {
+					for _, r := range records {
+						line := r.Query
+						if in.field > 0 {
+							line = r.Line
+						}
+						_, err := reject.WriteString(line + "\n")
+						checkError(err)
+					}
+				}
+			}
+		}
+		chDone <- 1
+	}()
+
+	// querying
+	var wg sync.WaitGroup
+	tokens := make(chan int, threads)
+	for chunk := range reader.Ch {
+		checkError(chunk.Err)
+		if atomic.LoadInt32(&stop) == 1 {
+			break
+		}
+		tokens <- 1
+		wg.Add(1)
+
+		lines := make([]record, len(chunk.Data))
+		for i, data := range chunk.Data {
+			lines[i] = data.(record)
+		}
+
+		go func(id uint64, lines []record) {
+			defer func() {
+				wg.Done()
+				<-tokens
+			}()
+
+			emit := func(i int, r record) {
+				chResults <- chunkRecords{id: id, index: i, record: r}
+			}
+			records, err := queryLines(query, lines, emit)
+			if err != nil {
+				records = lines
+			}
+			chResults <- chunkRecords{id: id, done: true, records: records, err: err}
+		}(chunk.ID, lines)
+	}
+	wg.Wait()
+	close(chResults)
+	<-chDone
+
+	if errStop != nil {
+		return errStop
+	}
+	if failedChunks > 0 {
+		if reject != nil {
+			log.Warningf("lines of failed chunks are written to: %s", in.rejectFile)
+		}
+		return fmt.Errorf("%d of %d chunks (%d lines) failed", failedChunks, next, failedLines)
+	}
+	return nil
+}
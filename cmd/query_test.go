@@ -0,0 +1,246 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shenwei356/gtaxon/taxon"
+)
+
+// writeQueryFile writes queries 1..n, one per line, to file in temporary directory
+func writeQueryFile(t *testing.T, n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%d\n", i)
+	}
+	file := filepath.Join(t.TempDir(), "queries.txt")
+	if err := os.WriteFile(file, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// echoQuery returns queries as taxids. Chunks of smaller queries are
+// slower, so that chunks finish in reverse order. Queries in fail fail.
+func echoQuery(fail map[string]bool) queryFunc {
+	return func(queries []string, emit func(i int, r record)) ([]record, error) {
+		first, _ := strconv.Atoi(queries[0])
+		time.Sleep(time.Duration(100-first) * 100 * time.Microsecond)
+		records := make([]record, len(queries))
+		for i, q := range queries {
+			if fail[q] {
+				return nil, fmt.Errorf("failed query: %s", q)
+			}
+			records[i] = record{Query: q, Found: true, TaxID: q}
+		}
+		return records, nil
+	}
+}
+
+func TestRunQueriesOrder(t *testing.T) {
+	file := writeQueryFile(t, 100)
+	var got []string
+	print := func(r record) { got = append(got, r.TaxID) }
+	if err := runQueries(echoQuery(nil), print, nil, file, inputOptions{delimiter: "\t"}, 3, 8); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 100 {
+		t.Fatalf("got %d records, want 100", len(got))
+	}
+	for i, taxid := range got {
+		if taxid != strconv.Itoa(i+1) {
+			t.Fatalf("record %d: got %s, want %d", i+1, taxid, i+1)
+		}
+	}
+}
+
+func TestRunQueriesFailedChunks(t *testing.T) {
+	file := writeQueryFile(t, 20)
+	fail := map[string]bool{"5": true, "14": true}
+
+	// stop at the first failed chunk, chunks before it are printed
+	var got []string
+	print := func(r record) { got = append(got, r.TaxID) }
+	err := runQueries(echoQuery(fail), print, nil, file, inputOptions{delimiter: "\t"}, 4, 4)
+	if err == nil {
+		t.Fatal("no error for failed chunk")
+	}
+	if strings.Join(got, ",") != "1,2,3,4" {
+		t.Errorf("got records %v before failed chunk", got)
+	}
+
+	// keep going, lines of failed chunks are written to reject file
+	got = got[:0]
+	rejectFile := filepath.Join(t.TempDir(), "rejected.txt")
+	in := inputOptions{delimiter: "\t", keepGoing: true, rejectFile: rejectFile}
+	if err = runQueries(echoQuery(fail), print, nil, file, in, 4, 4); err == nil {
+		t.Fatal("no error for failed chunks")
+	}
+	if strings.Join(got, ",") != "1,2,3,4,9,10,11,12,17,18,19,20" {
+		t.Errorf("got records %v", got)
+	}
+	rejected, err := os.ReadFile(rejectFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rejected) != "5\n6\n7\n8\n13\n14\n15\n16\n" {
+		t.Errorf("got rejected lines %q", rejected)
+	}
+}
+
+// streamQuery emits queries as taxids one by one, and fails at queries in fail
+// after emitting records before them
+func streamQuery(fail map[string]bool) queryFunc {
+	return func(queries []string, emit func(i int, r record)) ([]record, error) {
+		records := make([]record, len(queries))
+		for i, q := range queries {
+			if fail[q] {
+				return nil, fmt.Errorf("failed query: %s", q)
+			}
+			records[i] = record{Query: q, Found: true, TaxID: q}
+			if emit != nil {
+				emit(i, records[i])
+			}
+		}
+		return records, nil
+	}
+}
+
+func TestRunQueriesStreaming(t *testing.T) {
+	file := writeQueryFile(t, 20)
+	fail := map[string]bool{"14": true}
+
+	// records before failed query are printed, others are rejected
+	var got []string
+	print := func(r record) { got = append(got, r.TaxID) }
+	rejectFile := filepath.Join(t.TempDir(), "rejected.txt")
+	in := inputOptions{delimiter: "\t", keepGoing: true, rejectFile: rejectFile}
+	if err := runQueries(streamQuery(fail), print, nil, file, in, 4, 4); err == nil {
+		t.Fatal("no error for failed chunk")
+	}
+	if strings.Join(got, ",") != "1,2,3,4,5,6,7,8,9,10,11,12,13,17,18,19,20" {
+		t.Errorf("got records %v", got)
+	}
+	rejected, err := os.ReadFile(rejectFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rejected) != "14\n15\n16\n" {
+		t.Errorf("got rejected lines %q", rejected)
+	}
+}
+
+func TestQueryLinesEmit(t *testing.T) {
+	query := func(queries []string, emit func(i int, r record)) ([]record, error) {
+		for _, q := range queries {
+			if q == "a" {
+				return nil, &taxon.QueryError{Err: taxon.ErrInvalidQuery, Message: "non-digital taxid given: " + q, Queries: []string{q}}
+			}
+		}
+		return streamQuery(nil)(queries, emit)
+	}
+	lines := []record{{Query: "1", Line: "l1"}, {Query: "a", Line: "la"}, {Line: "#", PassThrough: true}, {Query: "2", Line: "l2"}}
+	emitted := make([]string, len(lines))
+	emit := func(i int, r record) {
+		emitted[i] = fmt.Sprintf("%s:%v:%s", r.Query, r.Found, r.Line)
+	}
+	if _, err := queryLines(query, lines, emit); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(emitted, " ") != "1:true:l1 a:false:la :false:# 2:true:l2" {
+		t.Errorf("got emitted records %v", emitted)
+	}
+}
+
+func TestQueryLinesInvalidQueries(t *testing.T) {
+	var requests int
+	query := func(queries []string, emit func(i int, r record)) ([]record, error) {
+		requests++
+		records := make([]record, len(queries))
+		for i, q := range queries {
+			if _, err := strconv.Atoi(strings.Split(q, ",")[0]); err != nil {
+				return nil, &taxon.QueryError{Err: taxon.ErrInvalidQuery, Message: "non-digital taxid given: " + q, Queries: []string{q}}
+			}
+			records[i] = record{Query: q, Found: true, TaxID: q}
+		}
+		return records, nil
+	}
+	lines := []record{{Query: "1"}, {Query: "a"}, {}, {Query: "2"}, {Query: "b"}, {Query: "3"}}
+	records, err := queryLines(query, lines, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range records {
+		got = append(got, fmt.Sprintf("%s:%v", r.Query, r.Found))
+	}
+	if strings.Join(got, " ") != "1:true a:false :false 2:true b:false 3:true" {
+		t.Errorf("got records %v", got)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+
+	// errors without offending queries are returned
+	_, err = queryLines(func(queries []string, emit func(i int, r record)) ([]record, error) {
+		return nil, &taxon.APIError{Code: taxon.CodeInvalidDB, Message: "unsupported database"}
+	}, []record{{Query: "1"}, {Query: "2"}}, nil)
+	if !errors.Is(err, taxon.ErrInvalidQuery) {
+		t.Errorf("got error %v, want ErrInvalidQuery", err)
+	}
+}
+
+func TestQueryLinesBatchTooLarge(t *testing.T) {
+	var maxBatch int
+	query := func(queries []string, emit func(i int, r record)) ([]record, error) {
+		if len(queries) > 3 {
+			return nil, &taxon.APIError{Code: taxon.CodeBatchTooLarge, Message: "too many queries"}
+		}
+		if len(queries) > maxBatch {
+			maxBatch = len(queries)
+		}
+		return echoQuery(nil)(queries, emit)
+	}
+	lines := make([]record, 10)
+	for i := range lines {
+		lines[i] = record{Query: strconv.Itoa(i + 1)}
+	}
+	records, err := queryLines(query, lines, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range records {
+		if r.TaxID != strconv.Itoa(i+1) {
+			t.Fatalf("record %d: got %s", i+1, r.TaxID)
+		}
+	}
+	if maxBatch > 3 {
+		t.Errorf("got batch of %d queries", maxBatch)
+	}
+}
@@ -21,15 +21,17 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
-	"sync"
 
-	"github.com/shenwei356/breader"
 	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/client"
 	"github.com/spf13/cobra"
 )
 
@@ -43,21 +45,28 @@ Available query types:
 
     gi_taxid_nucl      query TaxId by Gi (nucl)
     gi_taxid_prot      query TaxId by Gi (prot)
+    acc_taxid_nucl     query TaxId by accession (nucl)
+    acc_taxid_prot     query TaxId by accession (prot)
 
     taxid2taxon        query Taxon by TaxId
     name2taxid         query TaxId by Name
     lca                query Lowest Common Ancestor by TaxIds
 
+With "--transport grpc", queries are sent to gRPC service of server
+(gtaxon server --grpc-port) on host of --host or --url.
+
+Results are printed in the order of queries, one for every query
+(or line of file, including duplicates and blank lines), and
+"NA" for queries not found.
+
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		runtime.GOMAXPROCS(runtime.NumCPU())
 
 		dataType, err := cmd.Flags().GetString("type")
 		checkError(err)
-		host, err := cmd.Flags().GetString("host")
-		checkError(err)
-		port, err := cmd.Flags().GetInt("port")
-		checkError(err)
+		servers := getServerURLs(cmd)
+		server := strings.Join(servers, ", ")
 		dataFile, err := cmd.Flags().GetString("file")
 		checkError(err)
 		chunkSize, err := cmd.Flags().GetInt("chunk-size")
@@ -79,374 +88,116 @@ Available query types:
 		switch dataType {
 		case "":
 			log.Error("Flag -t/--type needed")
-
-		case "gi_taxid_nucl":
-			log.Info("Query database: %s from host: %s:%s", "gi_taxid_nucl", host, port)
-
-			if dataFile == "" {
-				remoteQueryGi2Taxid(host, port, "gi_taxid_prot", args)
-			} else {
-				remoteQueryGi2TaxidByFile(host, port, "gi_taxid_prot", dataFile, chunkSize, threads)
-			}
-
-		case "gi_taxid_prot":
-			log.Info("Query database: %s from host: %s:%d", "gi_taxid_prot", host, port)
-
-			if dataFile == "" {
-				remoteQueryGi2Taxid(host, port, "gi_taxid_prot", args)
-			} else {
-				remoteQueryGi2TaxidByFile(host, port, "gi_taxid_prot", dataFile, chunkSize, threads)
-			}
-
+			os.Exit(-1)
+		case "gi_taxid_nucl", "gi_taxid_prot", "acc_taxid_nucl", "acc_taxid_prot":
+			log.Infof("Query database: %s from server: %s", dataType, server)
 		case "taxid2taxon":
-			log.Info("Query Taxon by TaxId from host: %s:%d", host, port)
-
-			if dataFile == "" {
-				remoteQueryTaxid2Taxon(host, port, args)
-			} else {
-				remoteQueryTaxid2TaxonByFile(host, port, dataFile, chunkSize, threads)
-			}
-
+			log.Infof("Query Taxon by TaxId from server: %s", server)
 		case "name2taxid":
-			log.Info("Query TaxId by Name from host: %s:%d", host, port)
-
-			nameClass, err := cmd.Flags().GetString("name-class")
-			checkError(err)
-			useRegexp, err := cmd.Flags().GetBool("use-regexp")
-			checkError(err)
-			if dataFile == "" {
-				remoteName2TaxID(host, port, useRegexp, nameClass, args)
-			} else {
-				remoteName2TaxIDByFile(host, port, useRegexp, nameClass, dataFile, chunkSize, threads)
-			}
-
+			log.Infof("Query TaxId by Name from server: %s", server)
 		case "lca":
-			log.Info("Query LCA by TaxIds from host: %s:%d", host, port)
-
-			if dataFile == "" {
-				remoteQueryLCA(host, port, args)
-			} else {
-				remoteQueryLCAByFile(host, port, dataFile, chunkSize, threads)
-			}
-
+			log.Infof("Query LCA by TaxIds from server: %s", server)
 		default:
 			log.Errorf("Unsupported data type: %s", dataType)
 			os.Exit(-1)
 		}
-	},
-}
-
-// --------------------------------------------------------------------------
-
-func remoteQueryLCA(host string, port int, queries []string) {
-	msg := taxon.RemoteQueryLCA(host, port, queries)
-	if msg.Status != "OK" {
-		log.Error(msg.Message)
-	}
 
-	for query, taxon := range msg.LCA {
-		fmt.Printf("Query TaxIDs: %s\n", query)
-		bs, err := json.MarshalIndent(taxon, "", "  ")
+		nameClass, err := cmd.Flags().GetString("name-class")
+		checkError(err)
+		useRegexp, err := cmd.Flags().GetBool("use-regexp")
 		checkError(err)
-		fmt.Printf("Taxon: %s\n\n", string(bs))
-	}
-}
-
-func remoteQueryLCAByFile(host string, port int, dataFile string, chunkSize int, threads int) {
-	if chunkSize <= 0 {
-		chunkSize = 1000
-	}
-	fn := func(line string) (interface{}, bool, error) {
-		line = strings.TrimRight(line, "\n")
-		if line == "" {
-			return "", false, nil
-		}
-		return line, true, nil
-	}
-	reader, err := breader.NewBufferedReader(dataFile, threads, chunkSize, fn)
-	checkError(err)
-
-	chResults := make(chan taxon.MessageLCAMap, threads)
-
-	// receive result and print
-	chDone := make(chan int)
-	go func() {
-		for msg := range chResults {
-			if msg.Status != "OK" {
-				log.Error(msg.Message)
-			}
-			for query, taxon := range msg.LCA {
-				fmt.Printf("Query TaxIDs: %s\n", query)
-				bs, err := json.MarshalIndent(taxon, "", "  ")
-				checkError(err)
-				fmt.Printf("Taxon: %s\n\n", string(bs))
-			}
-		}
-		chDone <- 1
-	}()
-
-	// querying
-	var wg sync.WaitGroup
-	tokens := make(chan int, threads)
-	for chunk := range reader.Ch {
-		tokens <- 1
-		wg.Add(1)
 
-		queries := make([]string, len(chunk.Data))
-		for i, data := range chunk.Data {
-			queries[i] = data.(string)
+		transport, err := cmd.Flags().GetString("transport")
+		checkError(err)
+		switch transport {
+		case "rest", "grpc":
+		default:
+			log.Errorf("Unsupported transport: %s", transport)
+			os.Exit(-1)
 		}
-
-		go func(queries []string) {
-			defer func() {
-				wg.Done()
-				<-tokens
-			}()
-
-			msg := taxon.RemoteQueryLCA(host, port, queries)
-			checkError(err)
-			chResults <- msg
-		}(queries)
-	}
-	wg.Wait()
-	close(chResults)
-	<-chDone
-}
-
-// --------------------------------------------------------------------------
-
-func remoteQueryTaxid2Taxon(host string, port int, taxids []string) {
-	msg := taxon.RemoteQueryTaxid2Taxon(host, port, taxids)
-	if msg.Status != "OK" {
-		log.Error(msg.Message)
-	}
-
-	for taxid, taxon := range msg.Taxons {
-		fmt.Printf("Query TaxIDs: %s\n", taxid)
-		bs, err := json.MarshalIndent(taxon, "", "  ")
+		q := newRemoteQuerier(cmd, transport, servers)
+		defer q.Close()
+
+		in := getInputOptions(cmd)
+		out := newOutputter(cmd, dataType, in)
+		query := newQueryFunc(q, dataType, useRegexp, nameClass, out.needTaxons)
+		err = runQueries(query, out.print, args, dataFile, in, chunkSize, threads)
+		out.close()
 		checkError(err)
-		fmt.Printf("Taxon: %s\n\n", string(bs))
-	}
+	},
 }
 
-func remoteQueryTaxid2TaxonByFile(host string, port int, dataFile string, chunkSize int, threads int) {
-	if chunkSize <= 0 {
-		chunkSize = 1000
-	}
-	fn := func(line string) (interface{}, bool, error) {
-		line = strings.TrimRight(line, "\n")
-		if line == "" {
-			return "", false, nil
+// newRemoteQuerier creates querier of servers by transport "rest" or "grpc".
+// Failed queries are sent to the next server, and retried after all failed.
+func newRemoteQuerier(cmd *cobra.Command, transport string, servers []string) client.Querier {
+	queriers := make([]client.Querier, len(servers))
+	for i, server := range servers {
+		if transport == "grpc" {
+			queriers[i] = newGRPCQuerier(cmd, server)
+		} else {
+			queriers[i] = newRESTQuerier(cmd, server)
 		}
-		return line, true, nil
 	}
-	reader, err := breader.NewBufferedReader(dataFile, threads, chunkSize, fn)
+	q, err := client.NewFailover(queriers, getRetryOptions(cmd))
 	checkError(err)
-
-	chResults := make(chan taxon.MessageTaxid2TaxonMap, threads)
-
-	// receive result and print
-	chDone := make(chan int)
-	go func() {
-		for msg := range chResults {
-			if msg.Status != "OK" {
-				log.Error(msg.Message)
-			}
-			for taxid, taxon := range msg.Taxons {
-				fmt.Printf("Query TaxIDs: %s\n", taxid)
-				bs, err := json.MarshalIndent(taxon, "", "  ")
-				checkError(err)
-				fmt.Printf("Taxon: %s\n\n", string(bs))
-			}
-		}
-		chDone <- 1
-	}()
-
-	// querying
-	var wg sync.WaitGroup
-	tokens := make(chan int, threads)
-	for chunk := range reader.Ch {
-		tokens <- 1
-		wg.Add(1)
-
-		queries := make([]string, len(chunk.Data))
-		for i, data := range chunk.Data {
-			queries[i] = data.(string)
-		}
-
-		go func(queries []string) {
-			defer func() {
-				wg.Done()
-				<-tokens
-			}()
-
-			msg := taxon.RemoteQueryTaxid2Taxon(host, port, queries)
-			checkError(err)
-			chResults <- msg
-		}(queries)
-	}
-	wg.Wait()
-	close(chResults)
-	<-chDone
+	return q
 }
 
-// --------------------------------------------------------------------------
-
-func remoteName2TaxID(host string, port int, useRegexp bool, nameClass string, names []string) {
-	msg := taxon.RemoteQueryName2TaxID(host, port, useRegexp, nameClass, names)
-	if msg.Status != "OK" {
-		log.Error(msg.Message)
-	}
-
-	for name, items := range msg.TaxIDs {
-		idnames := make([]string, len(items))
-		for i, item := range items {
-			idnames[i] = fmt.Sprintf("%d(%s)", item.TaxID, item.ScientificName)
+// newRESTQuerier creates querier using REST APIs of server
+func newRESTQuerier(cmd *cobra.Command, server string) *client.Remote {
+	httpClient := taxon.NewHTTPClient(getClientTLSConfig(cmd))
+	r := client.NewRemote(server, httpClient)
+	// negotiated once here for the warning, or at the first query if failed
+	version, err := taxon.NegotiateAPIVersion(context.Background(), httpClient, server)
+	if err != nil {
+		log.Warningf("failed to ask API version of server %s: %s", server, err)
+	} else {
+		if version == "" {
+			log.Warningf("server %s does not support API %s, use deprecated legacy APIs", server, taxon.APIVersion)
 		}
-		fmt.Printf("%s\t%s\n", name, strings.Join(idnames, ","))
-	}
-}
-
-func remoteName2TaxIDByFile(host string, port int, useRegexp bool, nameClass string, dataFile string, chunkSize int, threads int) {
-	if chunkSize <= 0 {
-		chunkSize = 1000
+		r.SetAPIVersion(version)
 	}
-	fn := func(line string) (interface{}, bool, error) {
-		line = strings.TrimRight(line, "\n")
-		if line == "" {
-			return "", false, nil
-		}
-		return line, true, nil
-	}
-	reader, err := breader.NewBufferedReader(dataFile, threads, chunkSize, fn)
+	apiKey, err := cmd.Flags().GetString("api-key")
 	checkError(err)
-
-	chResults := make(chan taxon.MssageName2TaxIDMap, threads)
-
-	// receive result and print
-	chDone := make(chan int)
-	go func() {
-		for msg := range chResults {
-			if msg.Status != "OK" {
-				log.Error(msg.Message)
-			}
-			for name, items := range msg.TaxIDs {
-				idnames := make([]string, len(items))
-				for i, item := range items {
-					idnames[i] = fmt.Sprintf("%d(%s)", item.TaxID, item.ScientificName)
-				}
-				fmt.Printf("%s\t%s\n", name, strings.Join(idnames, ","))
-			}
-		}
-		chDone <- 1
-	}()
-
-	// querying
-	var wg sync.WaitGroup
-	tokens := make(chan int, threads)
-	for chunk := range reader.Ch {
-		tokens <- 1
-		wg.Add(1)
-
-		queries := make([]string, len(chunk.Data))
-		for i, data := range chunk.Data {
-			queries[i] = data.(string)
-		}
-
-		go func(queries []string) {
-			defer func() {
-				wg.Done()
-				<-tokens
-			}()
-
-			msg := taxon.RemoteQueryName2TaxID(host, port, useRegexp, nameClass, queries)
-			checkError(err)
-			chResults <- msg
-		}(queries)
-	}
-	wg.Wait()
-	close(chResults)
-	<-chDone
+	r.SetAPIKey(apiKey)
+	return r
 }
 
 // --------------------------------------------------------------------------
 
-func remoteQueryGi2Taxid(host string, port int, dataType string, gis []string) {
-	msg := taxon.RemoteQueryGi2Taxid(host, port, dataType, gis)
-	if msg.Status != "OK" {
-		log.Error(msg.Message)
-	}
-	for gi, taxid := range msg.Taxids {
-		fmt.Printf("%s\t%s\n", gi, taxid)
-	}
-}
-
-func remoteQueryGi2TaxidByFile(host string, port int, dataType string, dataFile string, chunkSize int, threads int) {
-	if chunkSize <= 0 {
-		chunkSize = 1000
-	}
-	fn := func(line string) (interface{}, bool, error) {
-		line = strings.TrimSpace(strings.TrimRight(line, "\n"))
-		if line == "" {
-			return "", false, nil
-		}
-		return line, true, nil
-	}
-	reader, err := breader.NewBufferedReader(dataFile, threads, chunkSize, fn)
+// newGRPCQuerier connects to gRPC service on host of server URL,
+// with TLS if the scheme is https
+func newGRPCQuerier(cmd *cobra.Command, server string) *client.GRPC {
+	port, err := cmd.Flags().GetInt("grpc-port")
 	checkError(err)
+	u, err := url.Parse(server)
+	checkError(err)
+	addr := net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
 
-	chResults := make(chan taxon.MessageGI2TaxidMap, threads)
-
-	// receive result and print
-	chDone := make(chan int)
-	go func() {
-		for msg := range chResults {
-			if msg.Status != "OK" {
-				log.Error(msg.Message)
-			}
-			for gi, taxid := range msg.Taxids {
-				fmt.Printf("%s\t%s\n", gi, taxid)
-			}
-		}
-		chDone <- 1
-	}()
-
-	// querying
-	var wg sync.WaitGroup
-	tokens := make(chan int, threads)
-	for chunk := range reader.Ch {
-		tokens <- 1
-		wg.Add(1)
-
-		gis := make([]string, len(chunk.Data))
-		for i, data := range chunk.Data {
-			gis[i] = data.(string)
-		}
-
-		go func(gis []string) {
-			defer func() {
-				wg.Done()
-				<-tokens
-			}()
-
-			msg := taxon.RemoteQueryGi2Taxid(host, port, dataType, gis)
-			checkError(err)
-			chResults <- msg
-		}(gis)
+	tlsConfig := getClientTLSConfig(cmd)
+	if tlsConfig == nil && u.Scheme == "https" {
+		tlsConfig = &tls.Config{}
 	}
-	wg.Wait()
-	close(chResults)
-	<-chDone
+	log.Infof("Query by gRPC from: %s", addr)
+	q, err := client.NewGRPC(addr, tlsConfig)
+	checkError(err)
+	apiKey, err := cmd.Flags().GetString("api-key")
+	checkError(err)
+	q.SetAPIKey(apiKey)
+	return q
 }
 
 func init() {
 	cliCmd.AddCommand(remoteCmd)
 
-	remoteCmd.Flags().StringP("host", "H", "127.0.0.1", "server host")
-	remoteCmd.Flags().IntP("port", "P", 8080, "port number")
+	addServerFlags(remoteCmd, "")
+	remoteCmd.Flags().StringP("transport", "", "rest", `transport: "rest" or "grpc"`)
+	remoteCmd.Flags().IntP("grpc-port", "", 8081, "port of gRPC service of server (only for --transport grpc)")
 	remoteCmd.Flags().StringP("type", "t", "", `query type. type "gataxon cli remote -h" for help`)
-	remoteCmd.Flags().StringP("file", "f", "", "read queries from file")
-	remoteCmd.Flags().IntP("chunk-size", "c", 10000, "chunk size of querying (should not be too small or too large, do not change this)")
+	remoteCmd.Flags().StringP("file", "f", "", `read queries from file, "-" for stdin`)
+	remoteCmd.Flags().IntP("chunk-size", "c", 10000, "chunk size of querying when reading queries from file, each chunk is sent in one POST request")
 	remoteCmd.Flags().BoolP("use-regexp", "R", false, `use regexp (only for query type "name2taxid")`)
 	remoteCmd.Flags().StringP("name-class", "C", "", `name class (only for query type "name2taxid")`)
+	addInputFlags(remoteCmd)
+	addOutputFlags(remoteCmd)
 }
@@ -23,6 +23,7 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -41,6 +42,11 @@ with cmd client ans REST API server for both local and remote server.
 Version: V0.2
 Detail: http://github.com/shenwei356/gtaxon
 `,
+	// flags not given in command line are set from environment
+	// variables or config file, see "gtaxon config -h"
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return applyConfig(cmd)
+	},
 }
 
 // Execute executes Execute
@@ -62,18 +68,23 @@ func init() {
 	var err error
 	homeDir, err = homedir.Expand("~")
 	checkError(err)
-	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", filepath.Join(homeDir, ".gtaxon.yaml"), "config file")
+	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", filepath.Join(homeDir, ".gtaxon.yaml"), `config file, or from environment variable GTAXON_CONFIG. see "gtaxon config -h"`)
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	if file, ok := os.LookupEnv("GTAXON_CONFIG"); ok && !RootCmd.PersistentFlags().Changed("config") {
+		cfgFile = file
+	}
 	if cfgFile != "" { // enable ability to specify config file via flag
 		viper.SetConfigFile(cfgFile)
+	} else {
+		viper.SetConfigName(".gtaxon") // name of config file (without extension)
+		viper.AddConfigPath("$HOME")   // adding home directory as first search path
 	}
-
-	viper.SetConfigName(".gtaxon") // name of config file (without extension)
-	viper.AddConfigPath("$HOME")   // adding home directory as first search path
-	// viper.AutomaticEnv()            // read in environment variables that match
+	viper.SetEnvPrefix("gtaxon") // e.g., GTAXON_SERVER_KEY_RATE for "server.key-rate"
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
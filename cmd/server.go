@@ -21,9 +21,13 @@
 package cmd
 
 import (
+	"fmt"
 	"runtime"
 
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/shenwei356/gtaxon/taxon"
 )
@@ -33,18 +37,73 @@ var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "start a web server",
 	Long: `start a web server with REST APIs,
-and you can use "gtaxon cli remote" to query from the server `,
+and you can use "gtaxon cli remote" to query from the server.
+
+Database could be reloaded without restarting server, e.g., after
+monthly update, by sending signal SIGHUP to the server process or
+POST request to /admin/reload (with flag --admin-token).
+Requests are served by the old data until the new one is loaded.
+
+API keys, rate limits and max batch sizes of query APIs could be
+configured in section "server" of config file (--config), e.g.,
+
+    server:
+      keys-file: /etc/gtaxon/keys.txt # "key [name [rate [burst]]]" per line
+      key-rate: 10    # max requests per second of one API key
+      key-burst: 20
+      ip-rate: 5      # max requests per second of one client IP
+      ip-burst: 10
+      max-batch:      # max number of queries in one request
+        gi2taxid: 100000
+        taxid2taxon: 100000
+        name2taxid: 10000
+        lca: 10000
+
+Clients should send API key by header "X-API-Key"
+("gtaxon cli remote --api-key"). Rates of 0 mean no limit.
+
+gRPC service (see taxon/pb/gtaxon.proto) is served on a separate port
+if --grpc-port is given, sharing data, TLS and limits with REST APIs.
+
+HTTPS is served if --tls-cert and --tls-key are given. With --tls-client-ca,
+clients should present certificates signed by the CAs
+("gtaxon cli remote --cert --key").
+`,
 	Run: func(cmd *cobra.Command, args []string) {
 		dbFilePath, _, _ := getDbFilePath(cmd)
 
 		port, err := cmd.Flags().GetInt("port")
 		checkError(err)
+		grpcPort, err := cmd.Flags().GetInt("grpc-port")
+		checkError(err)
 		timeout, err := cmd.Flags().GetInt("timeout")
 		checkError(err)
+		poolTimeout, err := cmd.Flags().GetInt("pool-timeout")
+		checkError(err)
 		threads, err := cmd.Flags().GetInt("threads")
 		checkError(err)
+		adminToken, err := cmd.Flags().GetString("admin-token")
+		checkError(err)
+		tlsCert, err := cmd.Flags().GetString("tls-cert")
+		checkError(err)
+		tlsKey, err := cmd.Flags().GetString("tls-key")
+		checkError(err)
+		tlsClientCA, err := cmd.Flags().GetString("tls-client-ca")
+		checkError(err)
 
-		taxon.StartServer(dbFilePath, port, timeout, threads)
+		checkError(taxon.StartServer(taxon.ServerOptions{
+			DBFilePath:  dbFilePath,
+			Port:        port,
+			GRPCPort:    grpcPort,
+			Threads:     threads,
+			Timeout:     timeout,
+			PoolTimeout: poolTimeout,
+			TLSCert:     tlsCert,
+			TLSKey:      tlsKey,
+			TLSClientCA: tlsClientCA,
+			AdminToken:  adminToken,
+			Limits:      getLimitOptions(),
+		}))
 	},
 }
 
@@ -52,6 +111,52 @@ func init() {
 	RootCmd.AddCommand(serverCmd)
 
 	serverCmd.Flags().IntP("port", "P", 8080, "port number")
-	serverCmd.Flags().IntP("timeout", "", 2, "request and responce time out (second)")
+	serverCmd.Flags().IntP("grpc-port", "", 0, "port number of gRPC service, e.g., 8081, 0 for disabled")
+	serverCmd.Flags().IntP("timeout", "", 60, "time out (second) of reading request, writing response and processing query, 0 for no limit")
+	serverCmd.Flags().IntP("pool-timeout", "", 5, "max time (second) waiting for database connection before answering 503, 0 for no limit")
 	serverCmd.Flags().IntP("threads", "j", runtime.NumCPU(), "max number of database connection")
+	serverCmd.Flags().StringP("tls-cert", "", "", "TLS certificate file (PEM) for serving HTTPS")
+	serverCmd.Flags().StringP("tls-key", "", "", "TLS key file (PEM) for serving HTTPS")
+	serverCmd.Flags().StringP("tls-client-ca", "", "", "CA bundle (PEM) verifying client certificates, clients without valid certificate are rejected (mutual TLS)")
+	serverCmd.Flags().StringP("admin-token", "", "", "bearer token for admin APIs like /admin/reload, admin APIs are disabled if not given")
+}
+
+// getLimitOptions reads limits of query APIs from section "server" of config file,
+// invalid values are fatal
+func getLimitOptions() taxon.LimitOptions {
+	keysFile, err := homedir.Expand(viper.GetString("server.keys-file"))
+	checkError(err)
+	opts := taxon.LimitOptions{
+		KeysFile: keysFile,
+		KeyRate:  configFloat64("server.key-rate"),
+		KeyBurst: configInt("server.key-burst"),
+		IPRate:   configFloat64("server.ip-rate"),
+		IPBurst:  configInt("server.ip-burst"),
+		MaxBatch: make(map[string]int),
+	}
+	for endpoint, n := range viper.GetStringMap("server.max-batch") {
+		opts.MaxBatch[endpoint], err = cast.ToIntE(n)
+		if err != nil {
+			checkError(fmt.Errorf("invalid value of server.max-batch.%s: %v", endpoint, n))
+		}
+	}
+	return opts
+}
+
+// configFloat64 returns float value of key in config file, invalid values are fatal
+func configFloat64(key string) float64 {
+	v, err := cast.ToFloat64E(viper.Get(key))
+	if err != nil {
+		checkError(fmt.Errorf("invalid value of %s: %v", key, viper.Get(key)))
+	}
+	return v
+}
+
+// configInt returns integer value of key in config file, invalid values are fatal
+func configInt(key string) int {
+	v, err := cast.ToIntE(viper.Get(key))
+	if err != nil {
+		checkError(fmt.Errorf("invalid value of %s: %v", key, viper.Get(key)))
+	}
+	return v
 }
@@ -0,0 +1,62 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Writing in-memory taxonomy to a snapshot file for fast loading",
+	Long: `Writing in-memory taxonomy (names, nodes, divisions, gencodes and name index)
+to a compact binary snapshot file "<db-file>.snapshot" in database directory.
+
+Server and local querying load the snapshot instead of decoding all records
+from database, when it's newer than the database file, which is much faster.
+Rerun this command after importing new data, otherwise the outdated snapshot
+is ignored.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			log.Error("No arguments needed for command: snapshot")
+			return
+		}
+
+		dbFilePath, _, _ := getDbFilePath(cmd)
+		outFile := taxon.SnapshotFile(dbFilePath)
+
+		pool, err := taxon.NewDBPool(dbFilePath, 4)
+		checkError(err)
+		defer pool.Close()
+		t, err := taxon.LoadTaxonomyFromDB(pool, nil)
+		checkError(err)
+
+		log.Infof("write snapshot: %s", outFile)
+		checkError(t.WriteSnapshot(outFile))
+		log.Infof("write snapshot: %s ... done", outFile)
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(snapshotCmd)
+}
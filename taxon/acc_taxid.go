@@ -0,0 +1,128 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	"github.com/shenwei356/breader"
+)
+
+// ImportAccTaxid reads nucl_*.accession2taxid or prot.accession2taxid file
+// and writes the data to database. Accessions are stored without version.
+func ImportAccTaxid(dbFile string, bucket string, dataFile string, chunkSize int, force bool) error {
+	db, err := bolt.Open(dbFile, 0600, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if force {
+		if err = deleteBucket(db, bucket); err != nil {
+			return err
+		}
+		log.Infof("Old database deleted: %s", bucket)
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = 1000000
+	}
+
+	// accession	accession.version	taxid	gi
+	fn := func(line string) (interface{}, bool, error) {
+		line = strings.TrimRight(line, "\n")
+		if line == "" || strings.HasPrefix(line, "accession\t") {
+			return nil, false, nil
+		}
+		items := strings.Split(line, "\t")
+		if len(items) < 3 {
+			return nil, false, nil
+		}
+		if items[0] == "" || items[2] == "" {
+			return nil, false, nil
+		}
+		return []string{items[0], items[2]}, true, nil
+	}
+
+	reader, err := breader.NewBufferedReader(dataFile, runtime.NumCPU(), chunkSize, fn)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for chunk := range reader.Ch {
+		if chunk.Err != nil {
+			return chunk.Err
+		}
+
+		records := make([][]string, len(chunk.Data))
+		for i, data := range chunk.Data {
+			records[i] = data.([]string)
+		}
+		if err = write2db(records, db, bucket); err != nil {
+			return err
+		}
+		n += len(records)
+		log.Infof("%d records imported to %s", n, dbFile)
+	}
+	return nil
+}
+
+var reAccVersion = regexp.MustCompile(`\.\d+$`)
+
+// TrimAccVersion removes the version suffix of an accession, e.g., "WP_000001.1" -> "WP_000001"
+func TrimAccVersion(acc string) string {
+	return reAccVersion.ReplaceAllString(acc, "")
+}
+
+// IsAccBucket tells whether the bucket stores accession-taxid pairs
+func IsAccBucket(bucket string) bool {
+	return bucket == "acc_taxid_nucl" || bucket == "acc_taxid_prot"
+}
+
+// QueryAcc2Taxid querys taxids by accessions, with or without version.
+// It stops when ctx is done.
+func QueryAcc2Taxid(ctx context.Context, db *bolt.DB, bucket string, accs []string) ([]string, error) {
+	taxids := make([]string, len(accs))
+	if len(accs) == 0 {
+		return taxids, nil
+	}
+
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
+		}
+		for i, acc := range accs {
+			if i%1000 == 0 && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			taxids[i] = string(b.Get([]byte(TrimAccVersion(acc))))
+		}
+		return nil
+	})
+	return taxids, err
+}
@@ -0,0 +1,147 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes in error objects of REST API
+const (
+	CodeInvalidQuery      = "invalid_query"       // malformed query values
+	CodeMissingQuery      = "missing_query"       // no query values given
+	CodeInvalidDB         = "invalid_db"          // unsupported db parameter
+	CodeInvalidBody       = "invalid_body"        // malformed JSON body
+	CodeDatabaseNotExists = "database_not_exists" // bucket not imported
+	CodeDatabaseNotReady  = "database_not_ready"  // database is still loading
+	CodeUnauthorized      = "unauthorized"        // invalid admin token or API key
+	CodeRateLimited       = "rate_limited"        // too many requests of API key or client IP
+	CodeBatchTooLarge     = "batch_too_large"     // too many queries in one request
+	CodeReloadInProgress  = "reload_in_progress"
+	CodeServerBusy        = "server_busy" // no database connection available in time
+	CodeTimeout           = "timeout"     // request timeout or cancelled
+	CodeInternal          = "internal_error"
+)
+
+// HTTP status codes of error codes
+var apiErrorStatus = map[string]int{
+	CodeInvalidQuery:      http.StatusBadRequest,
+	CodeMissingQuery:      http.StatusBadRequest,
+	CodeInvalidDB:         http.StatusBadRequest,
+	CodeInvalidBody:       http.StatusBadRequest,
+	CodeDatabaseNotExists: http.StatusNotFound,
+	CodeDatabaseNotReady:  http.StatusServiceUnavailable,
+	CodeUnauthorized:      http.StatusUnauthorized,
+	CodeRateLimited:       http.StatusTooManyRequests,
+	CodeBatchTooLarge:     http.StatusRequestEntityTooLarge,
+	CodeReloadInProgress:  http.StatusConflict,
+	CodeServerBusy:        http.StatusServiceUnavailable,
+	CodeTimeout:           http.StatusServiceUnavailable,
+	CodeInternal:          http.StatusInternalServerError,
+}
+
+// APIError is the error object in responses of REST API
+type APIError struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	Queries []string `json:"queries,omitempty"` // offending query values
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// StatusCode returns HTTP status code of the error
+func (e *APIError) StatusCode() int {
+	if code, ok := apiErrorStatus[e.Code]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// Unwrap returns the sentinel error of the error code,
+// so that errors.Is works for errors reported by server
+func (e *APIError) Unwrap() error {
+	switch e.Code {
+	case CodeInvalidQuery, CodeMissingQuery, CodeInvalidDB, CodeInvalidBody:
+		return ErrInvalidQuery
+	case CodeBatchTooLarge:
+		return ErrBatchTooLarge
+	case CodeDatabaseNotExists:
+		return ErrDatabaseNotExists
+	case CodeDatabaseNotReady:
+		return ErrDatabaseNotReady
+	}
+	return nil
+}
+
+// newAPIError converts error to APIError
+func newAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	e := &APIError{Code: CodeInternal, Message: err.Error()}
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		e.Queries = queryErr.Queries
+	}
+	switch {
+	case errors.Is(err, ErrInvalidQuery):
+		e.Code = CodeInvalidQuery
+	case errors.Is(err, ErrBatchTooLarge):
+		e.Code = CodeBatchTooLarge
+	case errors.Is(err, ErrDatabaseNotExists):
+		e.Code = CodeDatabaseNotExists
+	case errors.Is(err, ErrDatabaseNotReady):
+		e.Code = CodeDatabaseNotReady
+	case errors.Is(err, ErrPoolTimeout):
+		e.Code = CodeServerBusy
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		e.Code = CodeTimeout
+	}
+	return e
+}
+
+// MessageStatus holds common fields of response messages
+type MessageStatus struct {
+	Status  string    `json:"status"`
+	Message string    `json:"message"`
+	Error   *APIError `json:"error,omitempty"`
+}
+
+func (m *MessageStatus) setError(e *APIError) {
+	m.Status = "FAILED"
+	m.Message = e.Message
+	m.Error = e
+}
+
+// respondError responds msg carrying error object, with HTTP status code
+// of the error, msg should be pointer of response message
+func respondError(c *gin.Context, msg interface{ setError(*APIError) }, err error) {
+	e := newAPIError(err)
+	msg.setError(e)
+	c.JSON(e.StatusCode(), msg)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAPIErrorStatusAndSentinel(t *testing.T) {
+	for _, test := range []struct {
+		code     string
+		status   int
+		sentinel error
+	}{
+		{CodeInvalidQuery, http.StatusBadRequest, ErrInvalidQuery},
+		{CodeMissingQuery, http.StatusBadRequest, ErrInvalidQuery},
+		{CodeInvalidDB, http.StatusBadRequest, ErrInvalidQuery},
+		{CodeInvalidBody, http.StatusBadRequest, ErrInvalidQuery},
+		{CodeBatchTooLarge, http.StatusRequestEntityTooLarge, ErrBatchTooLarge},
+		{CodeDatabaseNotExists, http.StatusNotFound, ErrDatabaseNotExists},
+		{CodeDatabaseNotReady, http.StatusServiceUnavailable, ErrDatabaseNotReady},
+		{CodeUnauthorized, http.StatusUnauthorized, nil},
+		{CodeRateLimited, http.StatusTooManyRequests, nil},
+		{CodeReloadInProgress, http.StatusConflict, nil},
+		{CodeServerBusy, http.StatusServiceUnavailable, nil},
+		{CodeTimeout, http.StatusServiceUnavailable, nil},
+		{CodeInternal, http.StatusInternalServerError, nil},
+		{"unknown_code", http.StatusInternalServerError, nil},
+	} {
+		e := &APIError{Code: test.code, Message: "message"}
+		if got := e.StatusCode(); got != test.status {
+			t.Errorf("status of %s: got %d, want %d", test.code, got, test.status)
+		}
+		if got := e.Unwrap(); got != test.sentinel {
+			t.Errorf("sentinel of %s: got %v, want %v", test.code, got, test.sentinel)
+		}
+		if test.sentinel != nil && !errors.Is(fmt.Errorf("wrapped: %w", e), test.sentinel) {
+			t.Errorf("errors.Is(%s, %v) is false", test.code, test.sentinel)
+		}
+	}
+}
+
+func TestNewAPIError(t *testing.T) {
+	for _, test := range []struct {
+		err     error
+		code    string
+		queries []string
+	}{
+		{invalidQuery("non-digital taxid given: abc", "abc"), CodeInvalidQuery, []string{"abc"}},
+		{fmt.Errorf("%w: acc_taxid_prot", ErrDatabaseNotExists), CodeDatabaseNotExists, nil},
+		{ErrDatabaseNotReady, CodeDatabaseNotReady, nil},
+		{ErrBatchTooLarge, CodeBatchTooLarge, nil},
+		{ErrPoolTimeout, CodeServerBusy, nil},
+		{context.DeadlineExceeded, CodeTimeout, nil},
+		{context.Canceled, CodeTimeout, nil},
+		{errors.New("disk failure"), CodeInternal, nil},
+	} {
+		e := newAPIError(test.err)
+		if e.Code != test.code || !reflect.DeepEqual(e.Queries, test.queries) {
+			t.Errorf("%v: got code %s and queries %v, want %s and %v", test.err, e.Code, e.Queries, test.code, test.queries)
+		}
+		if e.Message != test.err.Error() {
+			t.Errorf("%v: got message %q", test.err, e.Message)
+		}
+	}
+
+	// APIError is kept as it is
+	e := &APIError{Code: CodeRateLimited, Message: "too many requests"}
+	if got := newAPIError(fmt.Errorf("wrapped: %w", e)); got != e {
+		t.Errorf("got %+v, want %+v", got, e)
+	}
+}
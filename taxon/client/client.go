@@ -0,0 +1,140 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+/*
+Package client provides a single query interface (Querier) for both local
+bolt database and remote gtaxon server, so that Go programs could query
+NCBI taxonomy data without shelling out to "gtaxon cli".
+
+	q := client.NewRemote("http://127.0.0.1:8080", nil)
+	taxids, err := q.Gi2TaxID(context.Background(), "gi_taxid_prot", []string{"139299181"})
+
+Remote queries by REST APIs, and GRPC queries by gRPC service of server
+(gtaxon server --grpc-port), which is faster for large batches.
+Remote uses REST API v1, or legacy APIs for old servers not supporting v1.
+Results of API v1 are received in NDJSON stream and decoded one by one,
+which could also be consumed as they arrive by methods of Streamer.
+Failover wraps Queriers of replicated servers, retrying failed queries
+on other servers and with exponential backoff.
+
+Results are in the same order of queries, with empty values for missing ones.
+Errors of malformed queries and missing databases wrap taxon.ErrInvalidQuery
+and taxon.ErrDatabaseNotExists respectively, which could be checked by
+errors.Is. Failures reported by server are returned as *RemoteError,
+carrying HTTP status code, stable error code and offending queries,
+and also wrap the sentinel errors above, or taxon.ErrBatchTooLarge
+for batches exceeding the max batch size of server.
+*/
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// Querier queries NCBI taxonomy data
+type Querier interface {
+	// Gi2TaxID querys taxids by GIs or accessions. dbType could be
+	// gi_taxid_nucl, gi_taxid_prot, acc_taxid_nucl or acc_taxid_prot
+	Gi2TaxID(ctx context.Context, dbType string, gis []string) ([]string, error)
+
+	// TaxID2Taxon querys Taxons by taxids
+	TaxID2Taxon(ctx context.Context, taxids []string) ([]nodes.Taxon, error)
+
+	// Name2TaxID querys taxids and scientific names by names,
+	// nameClass is optional
+	Name2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string) ([][]taxon.TaxIDSciNameItem, error)
+
+	// LCA querys Lowest Common Ancestors of groups of taxids
+	LCA(ctx context.Context, queries [][]string) ([]nodes.Taxon, error)
+
+	// Lineage querys lineages of taxids, from the child of root to the taxid itself
+	Lineage(ctx context.Context, taxids []string) ([][]nodes.LineageExItem, error)
+
+	// Close releases resources
+	Close() error
+}
+
+// Streamer is implemented by Queriers receiving results one by one,
+// e.g., Remote with NDJSON responses of API v1. fn is called for every
+// query as its result arrives, in the order of queries, with index of the query.
+type Streamer interface {
+	StreamGi2TaxID(ctx context.Context, dbType string, gis []string, fn func(i int, taxid string)) error
+	StreamTaxID2Taxon(ctx context.Context, taxids []string, fn func(i int, t nodes.Taxon)) error
+	StreamName2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string, fn func(i int, taxids []taxon.TaxIDSciNameItem)) error
+	StreamLCA(ctx context.Context, queries [][]string, fn func(i int, t nodes.Taxon)) error
+}
+
+// RemoteError is a failure reported by gtaxon server
+type RemoteError struct {
+	StatusCode int
+	Code       string // stable error code, e.g., taxon.CodeInvalidQuery
+	Message    string
+	Queries    []string // offending query values
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("gtaxon server: %s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// Unwrap maps error code to sentinel errors of package taxon,
+// e.g., errors.Is(err, taxon.ErrInvalidQuery) is true for code "invalid_query"
+func (e *RemoteError) Unwrap() error {
+	return (&taxon.APIError{Code: e.Code}).Unwrap()
+}
+
+// newRemoteError creates RemoteError from failed response message
+func newRemoteError(statusCode int, msg taxon.MessageStatus) *RemoteError {
+	e := &RemoteError{StatusCode: statusCode, Message: msg.Message}
+	if msg.Error != nil {
+		e.Code = msg.Error.Code
+		e.Queries = msg.Error.Queries
+	}
+	return e
+}
+
+func checkDbType(dbType string) error {
+	switch dbType {
+	case "gi_taxid_nucl", "gi_taxid_prot", "acc_taxid_nucl", "acc_taxid_prot":
+		return nil
+	}
+	return &taxon.QueryError{
+		Err:     taxon.ErrInvalidQuery,
+		Message: "unsupported database: " + dbType,
+		Queries: []string{dbType},
+	}
+}
+
+// lineageOf returns LineageEx of a Taxon, including itself
+func lineageOf(t nodes.Taxon) []nodes.LineageExItem {
+	if t.TaxId == 0 {
+		return []nodes.LineageExItem{}
+	}
+	lineage := make([]nodes.LineageExItem, 0, len(t.LineageEx)+1)
+	lineage = append(lineage, t.LineageEx...)
+	return append(lineage, nodes.LineageExItem{
+		TaxId:          t.TaxId,
+		ScientificName: t.ScientificName,
+		Rank:           t.Rank,
+	})
+}
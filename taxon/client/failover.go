@@ -0,0 +1,300 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// RetryOptions are options of retrying failed queries
+type RetryOptions struct {
+	// Retries is the max number of retries after all servers failed,
+	// 0 for no retry
+	Retries int
+	// Backoff is the time waiting before the first retry,
+	// doubled for every following retry, up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// RoundRobin starts every query from the next server in turn,
+	// otherwise servers are always tried in order
+	RoundRobin bool
+}
+
+// Failover queries by a list of Queriers, e.g., Remotes of replicated
+// servers. A failed query is sent to the next Querier, and retried with
+// exponential backoff after all of them failed. Only failures which may
+// be transient are retried, like network errors, server errors (HTTP 5xx)
+// and rate limiting (HTTP 429), while invalid queries are returned at once.
+// All query methods are idempotent, so retrying is safe.
+type Failover struct {
+	queriers []Querier
+	opts     RetryOptions
+	next     uint32 // next Querier of round robin
+}
+
+// NewFailover creates Failover of queriers
+func NewFailover(queriers []Querier, opts RetryOptions) (*Failover, error) {
+	if len(queriers) == 0 {
+		return nil, errors.New("client: no querier given")
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = 30 * time.Second
+		if opts.MaxBackoff < opts.Backoff {
+			opts.MaxBackoff = opts.Backoff
+		}
+	}
+	return &Failover{queriers: queriers, opts: opts}, nil
+}
+
+// do calls fn with Queriers in turn until it succeeds,
+// a permanent error occurs, or all retries fail
+func (f *Failover) do(ctx context.Context, fn func(q Querier) error) error {
+	start := 0
+	if f.opts.RoundRobin {
+		start = int(atomic.AddUint32(&f.next, 1)-1) % len(f.queriers)
+	}
+
+	backoff := f.opts.Backoff
+	var err error
+	for retry := 0; ; retry++ {
+		for i := range f.queriers {
+			err = fn(f.queriers[(start+i)%len(f.queriers)])
+			if err == nil || !retryable(ctx, err) {
+				return err
+			}
+		}
+		if retry == f.opts.Retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > f.opts.MaxBackoff {
+			backoff = f.opts.MaxBackoff
+		}
+	}
+	if f.opts.Retries > 0 {
+		return fmt.Errorf("%w (failed after %d retries)", err, f.opts.Retries)
+	}
+	return err
+}
+
+// retryable tells whether err may be transient: errors of server
+// with status code 5xx, 429 and 408, gRPC status of unavailable servers,
+// timeouts, refused or reset connections and truncated responses
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, taxon.ErrInvalidQuery) || errors.Is(err, taxon.ErrDatabaseNotExists) {
+		return false
+	}
+	var e *RemoteError
+	if errors.As(err, &e) {
+		return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests ||
+			e.StatusCode == http.StatusRequestTimeout
+	}
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+			return true
+		}
+		return false
+	}
+	// network errors
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Gi2TaxID querys taxids by GIs or accessions
+func (f *Failover) Gi2TaxID(ctx context.Context, dbType string, gis []string) (taxids []string, err error) {
+	err = f.do(ctx, func(q Querier) (err error) {
+		taxids, err = q.Gi2TaxID(ctx, dbType, gis)
+		return err
+	})
+	return taxids, err
+}
+
+// TaxID2Taxon querys Taxons by taxids
+func (f *Failover) TaxID2Taxon(ctx context.Context, taxids []string) (taxons []nodes.Taxon, err error) {
+	err = f.do(ctx, func(q Querier) (err error) {
+		taxons, err = q.TaxID2Taxon(ctx, taxids)
+		return err
+	})
+	return taxons, err
+}
+
+// Name2TaxID querys taxids and scientific names by names
+func (f *Failover) Name2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string) (items [][]taxon.TaxIDSciNameItem, err error) {
+	err = f.do(ctx, func(q Querier) (err error) {
+		items, err = q.Name2TaxID(ctx, useRegexp, nameClass, names)
+		return err
+	})
+	return items, err
+}
+
+// LCA querys Lowest Common Ancestors of groups of taxids
+func (f *Failover) LCA(ctx context.Context, queries [][]string) (lcas []nodes.Taxon, err error) {
+	err = f.do(ctx, func(q Querier) (err error) {
+		lcas, err = q.LCA(ctx, queries)
+		return err
+	})
+	return lcas, err
+}
+
+// Lineage querys lineages of taxids
+func (f *Failover) Lineage(ctx context.Context, taxids []string) (lineages [][]nodes.LineageExItem, err error) {
+	err = f.do(ctx, func(q Querier) (err error) {
+		lineages, err = q.Lineage(ctx, taxids)
+		return err
+	})
+	return lineages, err
+}
+
+// Streaming methods below call fn once for every query in order. If an
+// attempt fails after some results, results of these queries are skipped
+// at retrying. Results of Queriers not being Streamer arrive at once.
+
+// StreamGi2TaxID querys taxids by GIs or accessions,
+// fn is called for every query as its result arrives
+func (f *Failover) StreamGi2TaxID(ctx context.Context, dbType string, gis []string, fn func(i int, taxid string)) error {
+	var next int // index of next result to pass to fn
+	emit := func(i int, taxid string) {
+		if i == next {
+			fn(i, taxid)
+			next++
+		}
+	}
+	return f.do(ctx, func(q Querier) error {
+		if s, ok := q.(Streamer); ok {
+			return s.StreamGi2TaxID(ctx, dbType, gis, emit)
+		}
+		taxids, err := q.Gi2TaxID(ctx, dbType, gis)
+		for i, taxid := range taxids {
+			emit(i, taxid)
+		}
+		return err
+	})
+}
+
+// StreamTaxID2Taxon querys Taxons by taxids,
+// fn is called for every query as its result arrives
+func (f *Failover) StreamTaxID2Taxon(ctx context.Context, taxids []string, fn func(i int, t nodes.Taxon)) error {
+	var next int
+	emit := func(i int, t nodes.Taxon) {
+		if i == next {
+			fn(i, t)
+			next++
+		}
+	}
+	return f.do(ctx, func(q Querier) error {
+		if s, ok := q.(Streamer); ok {
+			return s.StreamTaxID2Taxon(ctx, taxids, emit)
+		}
+		taxons, err := q.TaxID2Taxon(ctx, taxids)
+		for i, t := range taxons {
+			emit(i, t)
+		}
+		return err
+	})
+}
+
+// StreamName2TaxID querys taxids and scientific names by names,
+// fn is called for every query as its result arrives
+func (f *Failover) StreamName2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string, fn func(i int, taxids []taxon.TaxIDSciNameItem)) error {
+	var next int
+	emit := func(i int, taxids []taxon.TaxIDSciNameItem) {
+		if i == next {
+			fn(i, taxids)
+			next++
+		}
+	}
+	return f.do(ctx, func(q Querier) error {
+		if s, ok := q.(Streamer); ok {
+			return s.StreamName2TaxID(ctx, useRegexp, nameClass, names, emit)
+		}
+		items, err := q.Name2TaxID(ctx, useRegexp, nameClass, names)
+		for i, taxids := range items {
+			emit(i, taxids)
+		}
+		return err
+	})
+}
+
+// StreamLCA querys Lowest Common Ancestors of groups of taxids,
+// fn is called for every query as its result arrives
+func (f *Failover) StreamLCA(ctx context.Context, queries [][]string, fn func(i int, t nodes.Taxon)) error {
+	var next int
+	emit := func(i int, t nodes.Taxon) {
+		if i == next {
+			fn(i, t)
+			next++
+		}
+	}
+	return f.do(ctx, func(q Querier) error {
+		if s, ok := q.(Streamer); ok {
+			return s.StreamLCA(ctx, queries, emit)
+		}
+		lcas, err := q.LCA(ctx, queries)
+		for i, t := range lcas {
+			emit(i, t)
+		}
+		return err
+	})
+}
+
+// Close closes all Queriers
+func (f *Failover) Close() error {
+	var err error
+	for _, q := range f.queriers {
+		if e := q.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
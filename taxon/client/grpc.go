@@ -0,0 +1,265 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+	"github.com/shenwei356/gtaxon/taxon/pb"
+)
+
+// GRPC queries from gtaxon server by gRPC service, results are received
+// by streaming methods, so there's no limit of message size.
+type GRPC struct {
+	conn   *grpc.ClientConn
+	client pb.GTaxonClient
+	apiKey string
+}
+
+// NewGRPC connects to gRPC service of gtaxon server at addr like
+// "127.0.0.1:8081". Plain text is used if tlsConfig is nil.
+func NewGRPC(addr string, tlsConfig *tls.Config) (*GRPC, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &GRPC{conn: conn, client: pb.NewGTaxonClient(conn)}, nil
+}
+
+// SetAPIKey sets API key sent in metadata "x-api-key" of every call
+func (g *GRPC) SetAPIKey(key string) {
+	g.apiKey = key
+}
+
+func (g *GRPC) context(ctx context.Context) context.Context {
+	if g.apiKey == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, taxon.GRPCAPIKeyMetadata, g.apiKey)
+}
+
+// receive calls recv until io.EOF
+func receive(recv func() error) error {
+	for {
+		err := recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return newGRPCError(err)
+		}
+	}
+}
+
+// newGRPCError converts gRPC status error to RemoteError
+func newGRPCError(err error) error {
+	e := taxon.APIErrorFromGRPC(err)
+	if e == nil {
+		return err
+	}
+	return &RemoteError{StatusCode: e.StatusCode(), Code: e.Code, Message: e.Message, Queries: e.Queries}
+}
+
+// Gi2TaxID querys taxids by GIs or accessions
+func (g *GRPC) Gi2TaxID(ctx context.Context, dbType string, gis []string) ([]string, error) {
+	if err := checkDbType(dbType); err != nil {
+		return nil, err
+	}
+	if len(gis) == 0 {
+		return []string{}, nil
+	}
+
+	molecule := pb.Molecule_PROT
+	if strings.HasSuffix(dbType, "_nucl") {
+		molecule = pb.Molecule_NUCL
+	}
+	var stream interface{ Recv() (*pb.TaxIDItem, error) }
+	var err error
+	if taxon.IsAccBucket(dbType) {
+		stream, err = g.client.Acc2TaxIDStream(g.context(ctx), &pb.Acc2TaxIDRequest{Molecule: molecule, Accessions: gis})
+	} else {
+		stream, err = g.client.Gi2TaxIDStream(g.context(ctx), &pb.Gi2TaxIDRequest{Molecule: molecule, Gis: gis})
+	}
+	if err != nil {
+		return nil, newGRPCError(err)
+	}
+
+	taxids := make([]string, 0, len(gis))
+	err = receive(func() error {
+		item, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if item.Taxid == 0 {
+			taxids = append(taxids, "")
+		} else {
+			taxids = append(taxids, strconv.FormatInt(item.Taxid, 10))
+		}
+		return nil
+	})
+	return taxids, err
+}
+
+// parseTaxIDs converts taxids to integers
+func parseTaxIDs(taxids []string) ([]int64, error) {
+	ids := make([]int64, len(taxids))
+	for i, taxid := range taxids {
+		id, err := strconv.ParseInt(taxid, 10, 64)
+		if err != nil {
+			return nil, &taxon.QueryError{
+				Err:     taxon.ErrInvalidQuery,
+				Message: "non-digital taxid given: " + taxid,
+				Queries: []string{taxid},
+			}
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// TaxID2Taxon querys Taxons by taxids
+func (g *GRPC) TaxID2Taxon(ctx context.Context, taxids []string) ([]nodes.Taxon, error) {
+	if len(taxids) == 0 {
+		return []nodes.Taxon{}, nil
+	}
+	ids, err := parseTaxIDs(taxids)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := g.client.TaxID2TaxonStream(g.context(ctx), &pb.TaxID2TaxonRequest{Taxids: ids})
+	if err != nil {
+		return nil, newGRPCError(err)
+	}
+	taxons := make([]nodes.Taxon, 0, len(taxids))
+	err = receive(func() error {
+		item, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		taxons = append(taxons, taxon.TaxonFromPB(item.Taxon))
+		return nil
+	})
+	return taxons, err
+}
+
+// Name2TaxID querys taxids and scientific names by names
+func (g *GRPC) Name2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string) ([][]taxon.TaxIDSciNameItem, error) {
+	if len(names) == 0 {
+		return [][]taxon.TaxIDSciNameItem{}, nil
+	}
+
+	req := &pb.Name2TaxIDRequest{Names: names, Regexp: useRegexp, NameClass: nameClass}
+	stream, err := g.client.Name2TaxIDStream(g.context(ctx), req)
+	if err != nil {
+		return nil, newGRPCError(err)
+	}
+	items := make([][]taxon.TaxIDSciNameItem, 0, len(names))
+	err = receive(func() error {
+		item, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		taxids := make([]taxon.TaxIDSciNameItem, len(item.Taxids))
+		for i, t := range item.Taxids {
+			taxids[i] = taxon.TaxIDSciNameItem{TaxID: int(t.Taxid), ScientificName: t.ScientificName}
+		}
+		items = append(items, taxids)
+		return nil
+	})
+	return items, err
+}
+
+// LCA querys Lowest Common Ancestors of groups of taxids
+func (g *GRPC) LCA(ctx context.Context, queries [][]string) ([]nodes.Taxon, error) {
+	if len(queries) == 0 {
+		return []nodes.Taxon{}, nil
+	}
+	groups := make([]*pb.TaxIDGroup, len(queries))
+	for i, taxids := range queries {
+		ids, err := parseTaxIDs(taxids)
+		if err != nil {
+			return nil, err
+		}
+		groups[i] = &pb.TaxIDGroup{Taxids: ids}
+	}
+
+	stream, err := g.client.LCAStream(g.context(ctx), &pb.LCARequest{Groups: groups})
+	if err != nil {
+		return nil, newGRPCError(err)
+	}
+	taxons := make([]nodes.Taxon, 0, len(queries))
+	err = receive(func() error {
+		item, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		taxons = append(taxons, taxon.TaxonFromPB(item.Lca))
+		return nil
+	})
+	return taxons, err
+}
+
+// Lineage querys lineages of taxids
+func (g *GRPC) Lineage(ctx context.Context, taxids []string) ([][]nodes.LineageExItem, error) {
+	if len(taxids) == 0 {
+		return [][]nodes.LineageExItem{}, nil
+	}
+	ids, err := parseTaxIDs(taxids)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := g.client.LineageStream(g.context(ctx), &pb.LineageRequest{Taxids: ids})
+	if err != nil {
+		return nil, newGRPCError(err)
+	}
+	lineages := make([][]nodes.LineageExItem, 0, len(taxids))
+	err = receive(func() error {
+		item, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		lineages = append(lineages, taxon.LineageFromPB(item.Lineage))
+		return nil
+	})
+	return lineages, err
+}
+
+// Close closes the connection
+func (g *GRPC) Close() error {
+	return g.conn.Close()
+}
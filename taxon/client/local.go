@@ -0,0 +1,170 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// Local queries from local bolt database. Names, nodes, divisions and
+// gencodes are loaded into memory at the first query other than Gi2TaxID.
+type Local struct {
+	pool    *taxon.DBPool
+	threads int
+
+	once     sync.Once
+	taxonomy *taxon.Taxonomy
+	errLoad  error
+}
+
+// NewLocal opens bolt database with at most threads connections
+func NewLocal(dbFilePath string, threads int) (*Local, error) {
+	if _, err := os.Stat(dbFilePath); err != nil {
+		return nil, err
+	}
+	if threads < 1 {
+		threads = 1
+	}
+	pool, err := taxon.NewDBPool(dbFilePath, threads)
+	if err != nil {
+		return nil, err
+	}
+	return &Local{pool: pool, threads: threads}, nil
+}
+
+func (l *Local) load() error {
+	l.once.Do(func() {
+		l.taxonomy, l.errLoad = taxon.LoadTaxonomy(l.pool)
+		if l.errLoad == nil {
+			l.taxonomy.Threads = l.threads
+		}
+	})
+	return l.errLoad
+}
+
+// Gi2TaxID querys taxids by GIs or accessions
+func (l *Local) Gi2TaxID(ctx context.Context, dbType string, gis []string) ([]string, error) {
+	if err := checkDbType(dbType); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db, err := l.pool.GetDBContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer l.pool.ReleaseDB(db)
+	if taxon.IsAccBucket(dbType) {
+		return taxon.QueryAcc2Taxid(ctx, db, dbType, gis)
+	}
+	return taxon.QueryGi2Taxid(ctx, db, dbType, gis)
+}
+
+// TaxID2Taxon querys Taxons by taxids
+func (l *Local) TaxID2Taxon(ctx context.Context, taxids []string) ([]nodes.Taxon, error) {
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	taxons, err := l.taxonomy.QueryTaxid2Taxon(taxids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]nodes.Taxon, len(taxids))
+	for i, taxid := range taxids {
+		result[i] = taxons[taxid]
+	}
+	return result, nil
+}
+
+// Name2TaxID querys taxids and scientific names by names
+func (l *Local) Name2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string) ([][]taxon.TaxIDSciNameItem, error) {
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results, err := l.taxonomy.QueryName2TaxID(ctx, useRegexp, nameClass, names)
+	if err != nil {
+		return nil, err
+	}
+	items := make([][]taxon.TaxIDSciNameItem, len(names))
+	for i, name := range names {
+		items[i] = results[name]
+	}
+	return items, nil
+}
+
+// LCA querys Lowest Common Ancestors of groups of taxids
+func (l *Local) LCA(ctx context.Context, queries [][]string) ([]nodes.Taxon, error) {
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	joined := make([]string, len(queries))
+	for i, taxids := range queries {
+		joined[i] = strings.Join(taxids, ",")
+	}
+	lcas, err := l.taxonomy.QueryLCA(joined)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]nodes.Taxon, len(queries))
+	for i, query := range joined {
+		result[i] = lcas[query]
+	}
+	return result, nil
+}
+
+// Lineage querys lineages of taxids
+func (l *Local) Lineage(ctx context.Context, taxids []string) ([][]nodes.LineageExItem, error) {
+	taxons, err := l.TaxID2Taxon(ctx, taxids)
+	if err != nil {
+		return nil, err
+	}
+	lineages := make([][]nodes.LineageExItem, len(taxons))
+	for i, t := range taxons {
+		lineages[i] = lineageOf(t)
+	}
+	return lineages, nil
+}
+
+// Close closes all database connections
+func (l *Local) Close() error {
+	l.pool.Close()
+	return nil
+}
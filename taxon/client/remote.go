@@ -0,0 +1,408 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shenwei356/gtaxon/taxon"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// Remote queries from gtaxon server by REST API. API version is
+// negotiated at the first query, legacy APIs are used for old servers
+// not supporting API v1. Results of API v1 are streamed in NDJSON.
+type Remote struct {
+	baseURL string
+	client  *http.Client
+	apiKey  string
+
+	mu      sync.Mutex // guards version
+	version *string    // negotiated API version, nil before negotiation
+}
+
+// NewRemote creates a Remote querier. baseURL is full URL of server, like
+// "http://127.0.0.1:8080" or "https://example.org/gtaxon" behind a reverse
+// proxy. http.DefaultClient is used if client is nil, use
+// taxon.NewHTTPClient for custom CA bundle or client certificate.
+func NewRemote(baseURL string, client *http.Client) *Remote {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Remote{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+// SetAPIKey sets API key sent in header "X-API-Key" of every request
+func (r *Remote) SetAPIKey(key string) {
+	r.apiKey = key
+}
+
+// SetAPIVersion sets API version of server negotiated by caller with
+// taxon.NegotiateAPIVersion, empty for legacy APIs, so that it's not
+// negotiated again at the first query
+func (r *Remote) SetAPIVersion(version string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.version = &version
+}
+
+// useV1 tells whether server supports API v1.
+// The version is cached once negotiated successfully.
+func (r *Remote) useV1(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.version == nil {
+		version, err := taxon.NegotiateAPIVersion(ctx, r.client, r.baseURL)
+		if err != nil {
+			return false, err
+		}
+		r.version = &version
+	}
+	return *r.version == taxon.APIVersion, nil
+}
+
+// request sends body in JSON by POST request, accepting media type of accept
+func (r *Remote) request(ctx context.Context, path string, body interface{}, accept string) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", r.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if r.apiKey != "" {
+		req.Header.Set(taxon.APIKeyHeader, r.apiKey)
+	}
+	return r.client.Do(req.WithContext(ctx))
+}
+
+// post sends body in JSON by POST request and decodes JSON response to v
+func (r *Remote) post(ctx context.Context, path string, body interface{}, v interface{}) (int, error) {
+	resp, err := r.request(ctx, path, body, "application/json")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return resp.StatusCode, &RemoteError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("invalid response: %s", err),
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// postStream posts body to endpoint of API v1 asking for NDJSON response,
+// and decodes n results line by line as they arrive, by calling decode
+// with index of every result. So results are not held in memory.
+func (r *Remote) postStream(ctx context.Context, endpoint string, body interface{}, n int, decode func(i int, dec *json.Decoder) error) error {
+	resp, err := r.request(ctx, "/"+taxon.APIVersion+endpoint, body, taxon.MIMENDJSON)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// failed requests are answered in normal JSON message
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), taxon.MIMENDJSON) {
+		var env taxon.V1Envelope
+		if err = json.NewDecoder(resp.Body).Decode(&env); err != nil {
+			return &RemoteError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("invalid response: %s", err)}
+		}
+		if env.Status != "OK" {
+			return newRemoteError(resp.StatusCode, env.MessageStatus())
+		}
+		return &RemoteError{StatusCode: resp.StatusCode, Message: "invalid response: streaming not supported by server"}
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for i := 0; i < n; i++ {
+		if err = decode(i, dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeResult decodes one line of NDJSON response to v,
+// a truncated response is reported as io.ErrUnexpectedEOF
+func decodeResult(dec *json.Decoder, v interface{}) error {
+	err := dec.Decode(v)
+	if err == io.EOF {
+		return fmt.Errorf("invalid response: %w", io.ErrUnexpectedEOF)
+	}
+	if err != nil {
+		return &RemoteError{StatusCode: http.StatusOK, Message: fmt.Sprintf("invalid response: %s", err)}
+	}
+	return nil
+}
+
+// streamError converts error object of NDJSON response to RemoteError
+func streamError(e *taxon.APIError) error {
+	return &RemoteError{StatusCode: e.StatusCode(), Code: e.Code, Message: e.Message, Queries: e.Queries}
+}
+
+// Gi2TaxID querys taxids by GIs or accessions
+func (r *Remote) Gi2TaxID(ctx context.Context, dbType string, gis []string) ([]string, error) {
+	taxids := make([]string, len(gis))
+	err := r.StreamGi2TaxID(ctx, dbType, gis, func(i int, taxid string) {
+		taxids[i] = taxid
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taxids, nil
+}
+
+// StreamGi2TaxID querys taxids by GIs or accessions,
+// fn is called for every query as its result arrives
+func (r *Remote) StreamGi2TaxID(ctx context.Context, dbType string, gis []string, fn func(i int, taxid string)) error {
+	if err := checkDbType(dbType); err != nil {
+		return err
+	}
+	if len(gis) == 0 {
+		return nil
+	}
+
+	req := taxon.Gi2TaxidRequest{DB: dbType, GIs: gis}
+	useV1, err := r.useV1(ctx)
+	if err != nil {
+		return err
+	}
+	if useV1 {
+		return r.postStream(ctx, "/gi2taxid", req, len(gis), func(i int, dec *json.Decoder) error {
+			var result taxon.V1TaxIDResult
+			if err := decodeResult(dec, &result); err != nil {
+				return err
+			}
+			if result.Error != nil {
+				return streamError(result.Error)
+			}
+			taxid := ""
+			if result.Found {
+				taxid = strconv.Itoa(result.TaxID)
+			}
+			fn(i, taxid)
+			return nil
+		})
+	}
+
+	var msg taxon.MessageGI2TaxidMap
+	code, err := r.post(ctx, "/gi2taxid", req, &msg)
+	if err != nil {
+		return err
+	}
+	if msg.Status != "OK" {
+		return newRemoteError(code, msg.MessageStatus)
+	}
+
+	for i, gi := range gis {
+		fn(i, msg.Taxids[gi])
+	}
+	return nil
+}
+
+// TaxID2Taxon querys Taxons by taxids
+func (r *Remote) TaxID2Taxon(ctx context.Context, taxids []string) ([]nodes.Taxon, error) {
+	taxons := make([]nodes.Taxon, len(taxids))
+	err := r.StreamTaxID2Taxon(ctx, taxids, func(i int, t nodes.Taxon) {
+		taxons[i] = t
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taxons, nil
+}
+
+// StreamTaxID2Taxon querys Taxons by taxids,
+// fn is called for every query as its result arrives
+func (r *Remote) StreamTaxID2Taxon(ctx context.Context, taxids []string, fn func(i int, t nodes.Taxon)) error {
+	if len(taxids) == 0 {
+		return nil
+	}
+
+	req := taxon.Taxid2TaxonRequest{TaxIDs: taxids}
+	useV1, err := r.useV1(ctx)
+	if err != nil {
+		return err
+	}
+	if useV1 {
+		return r.postStream(ctx, "/taxid2taxon", req, len(taxids), decodeTaxon(fn))
+	}
+
+	var msg taxon.MessageTaxid2TaxonMap
+	code, err := r.post(ctx, "/taxid2taxon", req, &msg)
+	if err != nil {
+		return err
+	}
+	if msg.Status != "OK" {
+		return newRemoteError(code, msg.MessageStatus)
+	}
+
+	for i, taxid := range taxids {
+		fn(i, msg.Taxons[taxid])
+	}
+	return nil
+}
+
+// decodeTaxon decodes results of /v1/taxid2taxon and /v1/lca, and calls fn
+func decodeTaxon(fn func(i int, t nodes.Taxon)) func(i int, dec *json.Decoder) error {
+	return func(i int, dec *json.Decoder) error {
+		var result taxon.V1TaxonResult
+		if err := decodeResult(dec, &result); err != nil {
+			return err
+		}
+		if result.Error != nil {
+			return streamError(result.Error)
+		}
+		fn(i, taxon.TaxonFromV1(result.Taxon))
+		return nil
+	}
+}
+
+// Name2TaxID querys taxids and scientific names by names
+func (r *Remote) Name2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string) ([][]taxon.TaxIDSciNameItem, error) {
+	items := make([][]taxon.TaxIDSciNameItem, len(names))
+	err := r.StreamName2TaxID(ctx, useRegexp, nameClass, names, func(i int, taxids []taxon.TaxIDSciNameItem) {
+		items[i] = taxids
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// StreamName2TaxID querys taxids and scientific names by names,
+// fn is called for every query as its result arrives
+func (r *Remote) StreamName2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string, fn func(i int, taxids []taxon.TaxIDSciNameItem)) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	req := taxon.Name2TaxIDRequest{Names: names, Regexp: useRegexp, Class: nameClass}
+	useV1, err := r.useV1(ctx)
+	if err != nil {
+		return err
+	}
+	if useV1 {
+		return r.postStream(ctx, "/name2taxid", req, len(names), func(i int, dec *json.Decoder) error {
+			var result taxon.V1Name2TaxIDResult
+			if err := decodeResult(dec, &result); err != nil {
+				return err
+			}
+			if result.Error != nil {
+				return streamError(result.Error)
+			}
+			fn(i, taxon.TaxIDNamesFromV1(result.TaxIDs))
+			return nil
+		})
+	}
+
+	var msg taxon.MssageName2TaxIDMap
+	code, err := r.post(ctx, "/name2taxid", req, &msg)
+	if err != nil {
+		return err
+	}
+	if msg.Status != "OK" {
+		return newRemoteError(code, msg.MessageStatus)
+	}
+
+	for i, name := range names {
+		fn(i, msg.TaxIDs[name])
+	}
+	return nil
+}
+
+// LCA querys Lowest Common Ancestors of groups of taxids
+func (r *Remote) LCA(ctx context.Context, queries [][]string) ([]nodes.Taxon, error) {
+	taxons := make([]nodes.Taxon, len(queries))
+	err := r.StreamLCA(ctx, queries, func(i int, t nodes.Taxon) {
+		taxons[i] = t
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taxons, nil
+}
+
+// StreamLCA querys Lowest Common Ancestors of groups of taxids,
+// fn is called for every query as its result arrives
+func (r *Remote) StreamLCA(ctx context.Context, queries [][]string, fn func(i int, t nodes.Taxon)) error {
+	if len(queries) == 0 {
+		return nil
+	}
+
+	joined := make([]string, len(queries))
+	for i, taxids := range queries {
+		joined[i] = strings.Join(taxids, ",")
+	}
+	req := taxon.LCARequest{TaxIDs: joined}
+	useV1, err := r.useV1(ctx)
+	if err != nil {
+		return err
+	}
+	if useV1 {
+		return r.postStream(ctx, "/lca", req, len(queries), decodeTaxon(fn))
+	}
+
+	var msg taxon.MessageLCAMap
+	code, err := r.post(ctx, "/lca", req, &msg)
+	if err != nil {
+		return err
+	}
+	if msg.Status != "OK" {
+		return newRemoteError(code, msg.MessageStatus)
+	}
+
+	for i, query := range joined {
+		fn(i, msg.LCA[query])
+	}
+	return nil
+}
+
+// Lineage querys lineages of taxids
+func (r *Remote) Lineage(ctx context.Context, taxids []string) ([][]nodes.LineageExItem, error) {
+	taxons, err := r.TaxID2Taxon(ctx, taxids)
+	if err != nil {
+		return nil, err
+	}
+	lineages := make([][]nodes.LineageExItem, len(taxons))
+	for i, t := range taxons {
+		lineages[i] = lineageOf(t)
+	}
+	return lineages, nil
+}
+
+// Close does nothing
+func (r *Remote) Close() error {
+	return nil
+}
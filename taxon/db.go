@@ -21,8 +21,10 @@
 package taxon
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/boltdb/bolt"
 	"github.com/mitchellh/go-homedir"
@@ -48,7 +50,7 @@ func init() {
 }
 
 // InitDatabase initializes database direcotry
-func InitDatabase(dbPath string, dbFile string, force bool) {
+func InitDatabase(dbPath string, dbFile string, force bool) error {
 	if dbPath == "" {
 		DbPath = defaultDbPath
 	} else {
@@ -61,52 +63,96 @@ func InitDatabase(dbPath string, dbFile string, force bool) {
 	}
 
 	existed, err := pathutil.DirExists(DbPath)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 	if existed {
-		if force {
-			os.RemoveAll(DbPath)
-			log.Info("Remove old dbPath and recreate: %s", DbPath)
-			os.MkdirAll(DbPath, os.ModePerm)
-		} else {
-			log.Info("Database directory (%s) existed. Nothing happended. Use --force to reinit", DbPath)
-			return
+		if !force {
+			log.Infof("Database directory (%s) existed. Nothing happened. Use --force to reinit", DbPath)
+			return nil
 		}
-	} else {
-		os.MkdirAll(DbPath, os.ModePerm)
+		if err = os.RemoveAll(DbPath); err != nil {
+			return err
+		}
+		log.Infof("Remove old dbPath and recreate: %s", DbPath)
 	}
+	return os.MkdirAll(DbPath, os.ModePerm)
 }
 
-var pool *DBPool
-
 // DBPool is bolt db connection pool
 type DBPool struct {
-	dbs []*bolt.DB
-	ch  chan *bolt.DB
+	path string
+	dbs  []*bolt.DB
+	ch   chan *bolt.DB
+
+	waitTimeout time.Duration
 }
 
 // NewDBPool is constructor for DBPools
-func NewDBPool(dbFilePath string, n int) *DBPool {
+func NewDBPool(dbFilePath string, n int) (*DBPool, error) {
 	pool := new(DBPool)
-	pool.dbs = make([]*bolt.DB, n)
+	pool.path = dbFilePath
+	pool.dbs = make([]*bolt.DB, 0, n)
 	pool.ch = make(chan *bolt.DB, n)
 
 	for i := 0; i < n; i++ {
 		db, err := bolt.Open(dbFilePath, 0600, &bolt.Options{ReadOnly: true})
-		checkError(err)
-		pool.dbs[i] = db
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to open database %s: %s", dbFilePath, err)
+		}
+		pool.dbs = append(pool.dbs, db)
 		pool.ch <- db
 	}
 
-	return pool
+	return pool, nil
+}
+
+// Path returns path of the database file
+func (p *DBPool) Path() string {
+	return p.path
 }
 
 // GetDB gets one connection
 func (p *DBPool) GetDB() *bolt.DB {
-	return <-p.ch
+	start := time.Now()
+	db := <-p.ch
+	dbPoolWaitSeconds.Observe(time.Since(start).Seconds())
+	dbPoolCheckedOut.Inc()
+	return db
+}
+
+// SetWaitTimeout sets the max time GetDBContext waits for a connection,
+// 0 for no limit
+func (p *DBPool) SetWaitTimeout(timeout time.Duration) {
+	p.waitTimeout = timeout
+}
+
+// GetDBContext gets one connection. It returns ErrPoolTimeout if no connection
+// is available within wait timeout of the pool, or error of ctx when it's done.
+func (p *DBPool) GetDBContext(ctx context.Context) (*bolt.DB, error) {
+	start := time.Now()
+	var timeout <-chan time.Time
+	if p.waitTimeout > 0 {
+		timer := time.NewTimer(p.waitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case db := <-p.ch:
+		dbPoolWaitSeconds.Observe(time.Since(start).Seconds())
+		dbPoolCheckedOut.Inc()
+		return db, nil
+	case <-timeout:
+		return nil, ErrPoolTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // ReleaseDB releases a connection
 func (p *DBPool) ReleaseDB(db *bolt.DB) {
+	dbPoolCheckedOut.Dec()
 	p.ch <- db
 }
 
@@ -33,14 +33,17 @@ import (
 )
 
 // ImportDivisions reads data from divisions.dmp and write to bolt database
-func ImportDivisions(dbFile string, bucket string, dataFile string, batchSize int, force bool) {
+func ImportDivisions(dbFile string, bucket string, dataFile string, batchSize int, force bool) error {
 	db, err := bolt.Open(dbFile, 0600, nil)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 	defer db.Close()
 
 	if force {
-		err = deleteBucket(db, bucket)
-		checkError(err)
+		if err = deleteBucket(db, bucket); err != nil {
+			return err
+		}
 		log.Info("Old database deleted: %s", bucket)
 	}
 
@@ -63,13 +66,14 @@ func ImportDivisions(dbFile string, bucket string, dataFile string, batchSize in
 	}
 
 	reader, err := breader.NewBufferedReader(dataFile, runtime.NumCPU(), batchSize, fn)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 
 	n := 0
 	for chunk := range reader.Ch {
 		if chunk.Err != nil {
-			checkError(chunk.Err)
-			return
+			return chunk.Err
 		}
 
 		records := make([][]string, len(chunk.Data))
@@ -77,22 +81,24 @@ func ImportDivisions(dbFile string, bucket string, dataFile string, batchSize in
 			division := data.(nodes.Division)
 			divisionJSONStr, err := division.ToJSON()
 			if err != nil {
-				checkError(chunk.Err)
-				return
+				return err
 			}
 			records[i] = []string{division.DivisionID, divisionJSONStr}
 		}
-		write2db(records, db, bucket)
+		if err = write2db(records, db, bucket); err != nil {
+			return err
+		}
 		n += len(records)
 		log.Info("%d records imported to %s", n, dbFile)
 	}
+	return nil
 }
 
 // QueryDivisionByDivisionID querys Division by taxid
 func QueryDivisionByDivisionID(db *bolt.DB, bucket string, ids []string) ([]nodes.Division, error) {
 	for _, id := range ids {
 		if !reDigitals.MatchString(id) {
-			return []nodes.Division{}, fmt.Errorf("non-digital division given: %s", id)
+			return []nodes.Division{}, invalidQuery("non-digital division given: "+id, id)
 		}
 	}
 	divisions := make([]nodes.Division, len(ids))
@@ -102,7 +108,7 @@ func QueryDivisionByDivisionID(db *bolt.DB, bucket string, ids []string) ([]node
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 		for i, id := range ids {
 			s := string(b.Get([]byte(id)))
@@ -127,10 +133,17 @@ func LoadAllDivisions(db *bolt.DB, bucket string) (map[string]nodes.Division, er
 
 	ch := make(chan string, runtime.NumCPU())
 	chDone := make(chan int)
+	var errParse error
 	go func() {
 		for s := range ch {
+			if errParse != nil { // just drain the channel
+				continue
+			}
 			division, err := nodes.DivisionFromJSON(s)
-			checkError(err)
+			if err != nil {
+				errParse = fmt.Errorf("failed to parse division record from database: %s", err)
+				continue
+			}
 			divisions[division.DivisionID] = division
 		}
 		chDone <- 1
@@ -139,17 +152,21 @@ func LoadAllDivisions(db *bolt.DB, bucket string) (map[string]nodes.Division, er
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 
-		b.ForEach(func(k, v []byte) error {
+		return b.ForEach(func(k, v []byte) error {
 			ch <- string(v)
 			return nil
 		})
-
-		return nil
 	})
 	close(ch)
 	<-chDone
-	return divisions, err
+	if err != nil {
+		return nil, err
+	}
+	if errParse != nil {
+		return nil, errParse
+	}
+	return divisions, nil
 }
@@ -0,0 +1,67 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidQuery is wrapped by errors of malformed queries,
+// e.g., non-digital taxids and invalid regular expressions
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrDatabaseNotExists is wrapped by errors of missing databases (buckets)
+var ErrDatabaseNotExists = errors.New("database not exists")
+
+// ErrDatabaseNotReady is wrapped by errors of querying
+// while database is still being loaded
+var ErrDatabaseNotReady = errors.New("database not ready")
+
+// ErrBatchTooLarge is wrapped by errors of requests with more queries
+// than the max batch size of server, which should be split
+var ErrBatchTooLarge = errors.New("batch too large")
+
+// ErrPoolTimeout is returned when no database connection
+// is available within the wait limit of DBPool
+var ErrPoolTimeout = errors.New("timeout waiting for database connection")
+
+// QueryError is an error caused by some of the queries,
+// Err is the sentinel error it wraps, usually ErrInvalidQuery
+type QueryError struct {
+	Err     error
+	Message string
+	Queries []string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err, e.Message)
+}
+
+// Unwrap returns the sentinel error
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// invalidQuery returns QueryError of ErrInvalidQuery with offending queries
+func invalidQuery(message string, queries ...string) error {
+	return &QueryError{Err: ErrInvalidQuery, Message: message, Queries: queries}
+}
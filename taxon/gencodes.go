@@ -33,14 +33,17 @@ import (
 )
 
 // ImportGenCodes reads data from gencodes.dmp and write to bolt database
-func ImportGenCodes(dbFile string, bucket string, dataFile string, batchSize int, force bool) {
+func ImportGenCodes(dbFile string, bucket string, dataFile string, batchSize int, force bool) error {
 	db, err := bolt.Open(dbFile, 0600, nil)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 	defer db.Close()
 
 	if force {
-		err = deleteBucket(db, bucket)
-		checkError(err)
+		if err = deleteBucket(db, bucket); err != nil {
+			return err
+		}
 		log.Info("Old database deleted: %s", bucket)
 	}
 
@@ -63,13 +66,14 @@ func ImportGenCodes(dbFile string, bucket string, dataFile string, batchSize int
 	}
 
 	reader, err := breader.NewBufferedReader(dataFile, runtime.NumCPU(), batchSize, fn)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 
 	n := 0
 	for chunk := range reader.Ch {
 		if chunk.Err != nil {
-			checkError(chunk.Err)
-			return
+			return chunk.Err
 		}
 
 		records := make([][]string, len(chunk.Data))
@@ -77,22 +81,24 @@ func ImportGenCodes(dbFile string, bucket string, dataFile string, batchSize int
 			gencode := data.(nodes.GenCode)
 			gencodeJSONStr, err := gencode.ToJSON()
 			if err != nil {
-				checkError(chunk.Err)
-				return
+				return err
 			}
 			records[i] = []string{gencode.GenCodeID, gencodeJSONStr}
 		}
-		write2db(records, db, bucket)
+		if err = write2db(records, db, bucket); err != nil {
+			return err
+		}
 		n += len(records)
 		log.Info("%d records imported to %s", n, dbFile)
 	}
+	return nil
 }
 
 // QueryGenCodeByGenCodeID querys GenCode by taxid
 func QueryGenCodeByGenCodeID(db *bolt.DB, bucket string, ids []string) ([]nodes.GenCode, error) {
 	for _, id := range ids {
 		if !reDigitals.MatchString(id) {
-			return []nodes.GenCode{}, fmt.Errorf("non-digital gencode given: %s", id)
+			return []nodes.GenCode{}, invalidQuery("non-digital gencode given: "+id, id)
 		}
 	}
 	gencodes := make([]nodes.GenCode, len(ids))
@@ -102,7 +108,7 @@ func QueryGenCodeByGenCodeID(db *bolt.DB, bucket string, ids []string) ([]nodes.
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 		for i, id := range ids {
 			s := string(b.Get([]byte(id)))
@@ -127,10 +133,17 @@ func LoadAllGenCodes(db *bolt.DB, bucket string) (map[string]nodes.GenCode, erro
 
 	ch := make(chan string, runtime.NumCPU())
 	chDone := make(chan int)
+	var errParse error
 	go func() {
 		for s := range ch {
+			if errParse != nil { // just drain the channel
+				continue
+			}
 			gencode, err := nodes.GenCodeFromJSON(s)
-			checkError(err)
+			if err != nil {
+				errParse = fmt.Errorf("failed to parse gencode record from database: %s", err)
+				continue
+			}
 			gencodes[gencode.GenCodeID] = gencode
 		}
 		chDone <- 1
@@ -139,17 +152,21 @@ func LoadAllGenCodes(db *bolt.DB, bucket string) (map[string]nodes.GenCode, erro
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 
-		b.ForEach(func(k, v []byte) error {
+		return b.ForEach(func(k, v []byte) error {
 			ch <- string(v)
 			return nil
 		})
-
-		return nil
 	})
 	close(ch)
 	<-chDone
-	return gencodes, err
+	if err != nil {
+		return nil, err
+	}
+	if errParse != nil {
+		return nil, errParse
+	}
+	return gencodes, nil
 }
@@ -21,6 +21,7 @@
 package taxon
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -31,14 +32,17 @@ import (
 )
 
 // ImportGiTaxid reads gi_taxid_nucl or gi_taxid_prot file and writes the data to database
-func ImportGiTaxid(dbFile string, bucket string, dataFile string, chunkSize int, force bool) {
+func ImportGiTaxid(dbFile string, bucket string, dataFile string, chunkSize int, force bool) error {
 	db, err := bolt.Open(dbFile, 0600, nil)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 	defer db.Close()
 
 	if force {
-		err = deleteBucket(db, bucket)
-		checkError(err)
+		if err = deleteBucket(db, bucket); err != nil {
+			return err
+		}
 		log.Info("Old database deleted: %s", bucket)
 	}
 
@@ -62,13 +66,14 @@ func ImportGiTaxid(dbFile string, bucket string, dataFile string, chunkSize int,
 	}
 
 	reader, err := breader.NewBufferedReader(dataFile, runtime.NumCPU(), chunkSize, fn)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 
 	n := 0
 	for chunk := range reader.Ch {
 		if chunk.Err != nil {
-			checkError(chunk.Err)
-			return
+			return chunk.Err
 		}
 
 		records := make([][]string, len(chunk.Data))
@@ -83,14 +88,17 @@ func ImportGiTaxid(dbFile string, bucket string, dataFile string, chunkSize int,
 				records[i] = items
 			}
 		}
-		write2db(records, db, bucket)
+		if err = write2db(records, db, bucket); err != nil {
+			return err
+		}
 		n += len(records)
 		log.Info("%d records imported to %s", n, dbFile)
 	}
+	return nil
 }
 
-// QueryGi2Taxid querys taxids by gis
-func QueryGi2Taxid(db *bolt.DB, bucket string, gis []string) ([]string, error) {
+// QueryGi2Taxid querys taxids by gis, it stops when ctx is done
+func QueryGi2Taxid(ctx context.Context, db *bolt.DB, bucket string, gis []string) ([]string, error) {
 	taxids := make([]string, len(gis))
 	if len(gis) == 0 {
 		return taxids, nil
@@ -99,9 +107,12 @@ func QueryGi2Taxid(db *bolt.DB, bucket string, gis []string) ([]string, error) {
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 		for i, gi := range gis {
+			if i%1000 == 0 && ctx.Err() != nil {
+				return ctx.Err()
+			}
 			taxids[i] = string(b.Get([]byte(gi)))
 		}
 		return nil
@@ -0,0 +1,603 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+	"github.com/shenwei356/gtaxon/taxon/pb"
+)
+
+// GRPCAPIKeyMetadata is the gRPC metadata key carrying API key
+const GRPCAPIKeyMetadata = "x-api-key"
+
+// GRPCErrorDomain is the domain of google.rpc.ErrorInfo in gRPC errors,
+// whose reason is the error code of REST API
+const GRPCErrorDomain = "gtaxon"
+
+// gRPC status codes of error codes
+var grpcErrorCodes = map[string]codes.Code{
+	CodeInvalidQuery:      codes.InvalidArgument,
+	CodeMissingQuery:      codes.InvalidArgument,
+	CodeInvalidDB:         codes.InvalidArgument,
+	CodeDatabaseNotExists: codes.NotFound,
+	CodeDatabaseNotReady:  codes.Unavailable,
+	CodeUnauthorized:      codes.Unauthenticated,
+	CodeRateLimited:       codes.ResourceExhausted,
+	CodeBatchTooLarge:     codes.InvalidArgument,
+	CodeServerBusy:        codes.Unavailable,
+	CodeTimeout:           codes.DeadlineExceeded,
+	CodeInternal:          codes.Internal,
+}
+
+// grpcError converts error to gRPC status error with google.rpc.ErrorInfo
+func grpcError(err error) error {
+	e := newAPIError(err)
+	code, ok := grpcErrorCodes[e.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	info := &errdetails.ErrorInfo{Reason: e.Code, Domain: GRPCErrorDomain}
+	if len(e.Queries) > 0 {
+		info.Metadata = map[string]string{"queries": strings.Join(e.Queries, "\n")}
+	}
+	st, _ := status.New(code, e.Message).WithDetails(info)
+	return st.Err()
+}
+
+// APIErrorFromGRPC converts error returned by gRPC service to APIError,
+// nil is returned if err is not a status error of gtaxon server
+func APIErrorFromGRPC(err error) *APIError {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == GRPCErrorDomain {
+			e := &APIError{Code: info.Reason, Message: st.Message()}
+			if queries := info.Metadata["queries"]; queries != "" {
+				e.Queries = strings.Split(queries, "\n")
+			}
+			return e
+		}
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+
+// startGRPC serves gRPC service of s on port in background,
+// the listener is created before returning, so that errors are reported early
+func (s *Server) startGRPC(port int, tlsConfig *tls.Config, limiter *Limiter, timeout time.Duration) error {
+	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return err
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(64 << 20),
+		grpc.ChainUnaryInterceptor(unaryInterceptor(limiter, timeout)),
+		grpc.ChainStreamInterceptor(streamInterceptor(limiter, timeout)),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	pb.RegisterGTaxonServer(server, &grpcService{s: s})
+
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			log.Errorf("gRPC server: %s", err)
+		}
+	}()
+	return nil
+}
+
+// grpc endpoints sharing max batch sizes with REST API
+var grpcEndpoints = map[string]string{
+	"Gi2TaxID":    "/gi2taxid",
+	"Acc2TaxID":   "/gi2taxid",
+	"TaxID2Taxon": "/taxid2taxon",
+	"Lineage":     "/taxid2taxon",
+	"Name2TaxID":  "/name2taxid",
+	"LCA":         "/lca",
+}
+
+type maxBatchSizeCtxKey struct{}
+
+// limitContext applies limits and timeout to context of gRPC call
+func limitContext(ctx context.Context, fullMethod string, limiter *Limiter, timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	var ip, key string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(GRPCAPIKeyMetadata); len(keys) > 0 {
+			key = keys[0]
+		}
+	}
+	if err := limiter.allow(ip, key); err != nil {
+		return nil, nil, grpcError(err)
+	}
+
+	method := strings.TrimSuffix(fullMethod[strings.LastIndex(fullMethod, "/")+1:], "Stream")
+	if n := limiter.maxBatch[grpcEndpoints[method]]; n > 0 {
+		ctx = context.WithValue(ctx, maxBatchSizeCtxKey{}, n)
+	}
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return ctx, cancel, nil
+	}
+	return ctx, func() {}, nil
+}
+
+func unaryInterceptor(limiter *Limiter, timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel, err := limitContext(ctx, info.FullMethod, limiter, timeout)
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// contextStream overrides context of grpc.ServerStream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamInterceptor(limiter *Limiter, timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel, err := limitContext(ss.Context(), info.FullMethod, limiter, timeout)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// --------------------------------------------------------------------------
+
+// grpcService implements pb.GTaxonServer with Taxonomy in service of Server
+type grpcService struct {
+	pb.UnimplementedGTaxonServer
+	s *Server
+}
+
+// acquire returns Taxonomy in service after checking number of queries,
+// release should be called after querying
+func (g *grpcService) acquire(ctx context.Context, n int) (t *Taxonomy, release func(), err error) {
+	if n == 0 {
+		return nil, nil, grpcError(&APIError{Code: CodeMissingQuery, Message: "no queries given"})
+	}
+	max, _ := ctx.Value(maxBatchSizeCtxKey{}).(int)
+	if err = batchSizeError(n, max); err != nil {
+		return nil, nil, grpcError(err)
+	}
+	gen := g.s.acquire()
+	if gen == nil {
+		return nil, nil, grpcError(errNotLoaded)
+	}
+	return gen.t, gen.wg.Done, nil
+}
+
+// chunks calls fn for every chunk of n queries, for streaming results
+func chunks(n int, fn func(start, end int) error) error {
+	for start := 0; start < n; start += streamChunkSize {
+		end := start + streamChunkSize
+		if end > n {
+			end = n
+		}
+		if err := fn(start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bucketOf(prefix string, molecule pb.Molecule) string {
+	if molecule == pb.Molecule_NUCL {
+		return prefix + "_nucl"
+	}
+	return prefix + "_prot"
+}
+
+func (t *Taxonomy) grpcTaxIDs(ctx context.Context, bucket string, queries []string) ([]*pb.TaxIDItem, error) {
+	taxids, err := t.QueryGi2Taxid(ctx, bucket, queries)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.TaxIDItem, len(queries))
+	for i, query := range queries {
+		taxid, _ := strconv.ParseInt(taxids[i], 10, 64)
+		items[i] = &pb.TaxIDItem{Query: query, Taxid: taxid}
+	}
+	return items, nil
+}
+
+func (g *grpcService) taxIDs(ctx context.Context, bucket string, queries []string) (*pb.TaxIDResponse, error) {
+	t, release, err := g.acquire(ctx, len(queries))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	items, err := t.grpcTaxIDs(ctx, bucket, queries)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.TaxIDResponse{Items: items}, nil
+}
+
+func (g *grpcService) streamTaxIDs(ctx context.Context, bucket string, queries []string, send func(*pb.TaxIDItem) error) error {
+	t, release, err := g.acquire(ctx, len(queries))
+	if err != nil {
+		return err
+	}
+	defer release()
+	return chunks(len(queries), func(start, end int) error {
+		items, err := t.grpcTaxIDs(ctx, bucket, queries[start:end])
+		if err != nil {
+			return grpcError(err)
+		}
+		for _, item := range items {
+			if err = send(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Gi2TaxID querys taxids by GIs
+func (g *grpcService) Gi2TaxID(ctx context.Context, req *pb.Gi2TaxIDRequest) (*pb.TaxIDResponse, error) {
+	return g.taxIDs(ctx, bucketOf("gi_taxid", req.Molecule), req.Gis)
+}
+
+// Gi2TaxIDStream querys taxids by GIs
+func (g *grpcService) Gi2TaxIDStream(req *pb.Gi2TaxIDRequest, stream pb.GTaxon_Gi2TaxIDStreamServer) error {
+	return g.streamTaxIDs(stream.Context(), bucketOf("gi_taxid", req.Molecule), req.Gis, stream.Send)
+}
+
+// Acc2TaxID querys taxids by accessions
+func (g *grpcService) Acc2TaxID(ctx context.Context, req *pb.Acc2TaxIDRequest) (*pb.TaxIDResponse, error) {
+	return g.taxIDs(ctx, bucketOf("acc_taxid", req.Molecule), req.Accessions)
+}
+
+// Acc2TaxIDStream querys taxids by accessions
+func (g *grpcService) Acc2TaxIDStream(req *pb.Acc2TaxIDRequest, stream pb.GTaxon_Acc2TaxIDStreamServer) error {
+	return g.streamTaxIDs(stream.Context(), bucketOf("acc_taxid", req.Molecule), req.Accessions, stream.Send)
+}
+
+// --------------------------------------------------------------------------
+
+func formatTaxIDs(taxids []int64) []string {
+	s := make([]string, len(taxids))
+	for i, taxid := range taxids {
+		s[i] = strconv.FormatInt(taxid, 10)
+	}
+	return s
+}
+
+func (t *Taxonomy) grpcTaxons(taxids []int64) ([]*pb.TaxID2TaxonItem, error) {
+	queries := formatTaxIDs(taxids)
+	taxons, err := t.QueryTaxid2Taxon(queries)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.TaxID2TaxonItem, len(taxids))
+	for i, taxid := range taxids {
+		items[i] = &pb.TaxID2TaxonItem{Taxid: taxid, Taxon: taxonToPB(taxons[queries[i]])}
+	}
+	return items, nil
+}
+
+// TaxID2Taxon querys Taxons by taxids
+func (g *grpcService) TaxID2Taxon(ctx context.Context, req *pb.TaxID2TaxonRequest) (*pb.TaxID2TaxonResponse, error) {
+	t, release, err := g.acquire(ctx, len(req.Taxids))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	items, err := t.grpcTaxons(req.Taxids)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.TaxID2TaxonResponse{Items: items}, nil
+}
+
+// TaxID2TaxonStream querys Taxons by taxids
+func (g *grpcService) TaxID2TaxonStream(req *pb.TaxID2TaxonRequest, stream pb.GTaxon_TaxID2TaxonStreamServer) error {
+	t, release, err := g.acquire(stream.Context(), len(req.Taxids))
+	if err != nil {
+		return err
+	}
+	defer release()
+	return chunks(len(req.Taxids), func(start, end int) error {
+		items, err := t.grpcTaxons(req.Taxids[start:end])
+		if err != nil {
+			return grpcError(err)
+		}
+		for _, item := range items {
+			if err = stream.Send(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// --------------------------------------------------------------------------
+
+func (t *Taxonomy) grpcName2TaxIDs(ctx context.Context, req *pb.Name2TaxIDRequest, names []string) ([]*pb.Name2TaxIDItem, error) {
+	results, err := t.QueryName2TaxID(ctx, req.Regexp, req.NameClass, names)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.Name2TaxIDItem, len(names))
+	for i, name := range names {
+		taxids := make([]*pb.TaxIDName, len(results[name]))
+		for j, r := range results[name] {
+			taxids[j] = &pb.TaxIDName{Taxid: int64(r.TaxID), ScientificName: r.ScientificName}
+		}
+		items[i] = &pb.Name2TaxIDItem{Query: name, Taxids: taxids}
+	}
+	return items, nil
+}
+
+// Name2TaxID querys taxids and scientific names by names
+func (g *grpcService) Name2TaxID(ctx context.Context, req *pb.Name2TaxIDRequest) (*pb.Name2TaxIDResponse, error) {
+	t, release, err := g.acquire(ctx, len(req.Names))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	items, err := t.grpcName2TaxIDs(ctx, req, req.Names)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.Name2TaxIDResponse{Items: items}, nil
+}
+
+// Name2TaxIDStream querys taxids and scientific names by names
+func (g *grpcService) Name2TaxIDStream(req *pb.Name2TaxIDRequest, stream pb.GTaxon_Name2TaxIDStreamServer) error {
+	ctx := stream.Context()
+	t, release, err := g.acquire(ctx, len(req.Names))
+	if err != nil {
+		return err
+	}
+	defer release()
+	return chunks(len(req.Names), func(start, end int) error {
+		items, err := t.grpcName2TaxIDs(ctx, req, req.Names[start:end])
+		if err != nil {
+			return grpcError(err)
+		}
+		for _, item := range items {
+			if err = stream.Send(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// --------------------------------------------------------------------------
+
+func (t *Taxonomy) grpcLCAs(groups []*pb.TaxIDGroup) ([]*pb.LCAItem, error) {
+	queries := make([]string, len(groups))
+	for i, group := range groups {
+		queries[i] = strings.Join(formatTaxIDs(group.Taxids), ",")
+	}
+	lcas, err := t.QueryLCA(queries)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.LCAItem, len(groups))
+	for i, group := range groups {
+		items[i] = &pb.LCAItem{Taxids: group.Taxids, Lca: taxonToPB(lcas[queries[i]])}
+	}
+	return items, nil
+}
+
+// LCA querys Lowest Common Ancestors of groups of taxids
+func (g *grpcService) LCA(ctx context.Context, req *pb.LCARequest) (*pb.LCAResponse, error) {
+	t, release, err := g.acquire(ctx, len(req.Groups))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	items, err := t.grpcLCAs(req.Groups)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.LCAResponse{Items: items}, nil
+}
+
+// LCAStream querys Lowest Common Ancestors of groups of taxids
+func (g *grpcService) LCAStream(req *pb.LCARequest, stream pb.GTaxon_LCAStreamServer) error {
+	t, release, err := g.acquire(stream.Context(), len(req.Groups))
+	if err != nil {
+		return err
+	}
+	defer release()
+	return chunks(len(req.Groups), func(start, end int) error {
+		items, err := t.grpcLCAs(req.Groups[start:end])
+		if err != nil {
+			return grpcError(err)
+		}
+		for _, item := range items {
+			if err = stream.Send(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// --------------------------------------------------------------------------
+
+func (t *Taxonomy) grpcLineages(taxids []int64) ([]*pb.LineageItem, error) {
+	queries := formatTaxIDs(taxids)
+	taxons, err := t.QueryTaxid2Taxon(queries)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*pb.LineageItem, len(taxids))
+	for i, taxid := range taxids {
+		items[i] = &pb.LineageItem{Taxid: taxid, Lineage: lineageToPB(taxons[queries[i]])}
+	}
+	return items, nil
+}
+
+// Lineage querys lineages of taxids
+func (g *grpcService) Lineage(ctx context.Context, req *pb.LineageRequest) (*pb.LineageResponse, error) {
+	t, release, err := g.acquire(ctx, len(req.Taxids))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	items, err := t.grpcLineages(req.Taxids)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &pb.LineageResponse{Items: items}, nil
+}
+
+// LineageStream querys lineages of taxids
+func (g *grpcService) LineageStream(req *pb.LineageRequest, stream pb.GTaxon_LineageStreamServer) error {
+	t, release, err := g.acquire(stream.Context(), len(req.Taxids))
+	if err != nil {
+		return err
+	}
+	defer release()
+	return chunks(len(req.Taxids), func(start, end int) error {
+		items, err := t.grpcLineages(req.Taxids[start:end])
+		if err != nil {
+			return grpcError(err)
+		}
+		for _, item := range items {
+			if err = stream.Send(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// --------------------------------------------------------------------------
+
+// taxonToPB converts Taxon to protobuf message, nil for missing Taxon
+func taxonToPB(t nodes.Taxon) *pb.Taxon {
+	if t.TaxId == 0 {
+		return nil
+	}
+	otherNames := make([]*pb.OtherName, len(t.OtherNames))
+	for i, name := range t.OtherNames {
+		otherNames[i] = &pb.OtherName{NameClass: name.ClassCDE, Name: name.DispName}
+	}
+	lineageEx := make([]*pb.LineageNode, len(t.LineageEx))
+	for i, item := range t.LineageEx {
+		lineageEx[i] = lineageNodeToPB(item)
+	}
+	return &pb.Taxon{
+		Taxid:           int64(t.TaxId),
+		ScientificName:  t.ScientificName,
+		OtherNames:      otherNames,
+		ParentTaxid:     int64(t.ParentTaxId),
+		Rank:            t.Rank,
+		Division:        t.Division,
+		GeneticCode:     &pb.GeneticCode{Id: int32(t.GeneticCode.GCId), Name: t.GeneticCode.GCName},
+		MitoGeneticCode: &pb.GeneticCode{Id: int32(t.MitoGeneticCode.MGCId), Name: t.MitoGeneticCode.MGCName},
+		Lineage:         t.Lineage,
+		LineageEx:       lineageEx,
+	}
+}
+
+// TaxonFromPB converts protobuf message to Taxon, empty Taxon for nil
+func TaxonFromPB(t *pb.Taxon) nodes.Taxon {
+	if t == nil {
+		return nodes.Taxon{}
+	}
+	otherNames := make([]nodes.TaxonNameItem, len(t.OtherNames))
+	for i, name := range t.OtherNames {
+		otherNames[i] = nodes.TaxonNameItem{ClassCDE: name.NameClass, DispName: name.Name}
+	}
+	return nodes.Taxon{
+		TaxId:           int(t.Taxid),
+		ScientificName:  t.ScientificName,
+		OtherNames:      otherNames,
+		ParentTaxId:     int(t.ParentTaxid),
+		Rank:            t.Rank,
+		Division:        t.Division,
+		GeneticCode:     nodes.GeneticCodeItem{GCId: int(t.GeneticCode.GetId()), GCName: t.GeneticCode.GetName()},
+		MitoGeneticCode: nodes.MitoGeneticCodeItem{MGCId: int(t.MitoGeneticCode.GetId()), MGCName: t.MitoGeneticCode.GetName()},
+		Lineage:         t.Lineage,
+		LineageEx:       LineageFromPB(t.LineageEx),
+	}
+}
+
+func lineageNodeToPB(item nodes.LineageExItem) *pb.LineageNode {
+	return &pb.LineageNode{Taxid: int64(item.TaxId), ScientificName: item.ScientificName, Rank: item.Rank}
+}
+
+// lineageToPB returns LineageEx of a Taxon including itself
+func lineageToPB(t nodes.Taxon) []*pb.LineageNode {
+	if t.TaxId == 0 {
+		return nil
+	}
+	lineage := make([]*pb.LineageNode, 0, len(t.LineageEx)+1)
+	for _, item := range t.LineageEx {
+		lineage = append(lineage, lineageNodeToPB(item))
+	}
+	return append(lineage, &pb.LineageNode{Taxid: int64(t.TaxId), ScientificName: t.ScientificName, Rank: t.Rank})
+}
+
+// LineageFromPB converts protobuf messages to LineageExItems
+func LineageFromPB(lineage []*pb.LineageNode) []nodes.LineageExItem {
+	items := make([]nodes.LineageExItem, len(lineage))
+	for i, node := range lineage {
+		items[i] = nodes.LineageExItem{TaxId: int(node.Taxid), ScientificName: node.ScientificName, Rank: node.Rank}
+	}
+	return items
+}
@@ -0,0 +1,309 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// APIKeyHeader is the HTTP header carrying API key
+const APIKeyHeader = "X-API-Key"
+
+// LimitOptions is options of access control and rate limiting of query APIs
+type LimitOptions struct {
+	// KeysFile is the file of API keys. If given, every query should carry
+	// a valid API key in header "X-API-Key". Each line is
+	// "key [name [rate [burst]]]", separated by spaces or tabs,
+	// blank lines and lines starting with "#" are ignored.
+	KeysFile string
+
+	// KeyRate is the max requests per second of one API key, 0 for no limit.
+	// It could be overridden by rate column in KeysFile
+	KeyRate  float64
+	KeyBurst int
+
+	// IPRate is the max requests per second of one client IP, 0 for no limit
+	IPRate  float64
+	IPBurst int
+
+	// MaxBatch is the max number of queries in one request by endpoint,
+	// e.g. {"name2taxid": 1000}. Missing or 0 for no limit
+	MaxBatch map[string]int
+}
+
+// apiKey is one record of keys file
+type apiKey struct {
+	key   string
+	name  string
+	rate  float64
+	burst int
+}
+
+// readAPIKeys reads API keys from file, rate and burst of keys are
+// defaultRate and defaultBurst if not given in file
+func readAPIKeys(file string, defaultRate float64, defaultBurst int) ([]apiKey, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	var keys []apiKey
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(fh)
+	var n int
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		items := strings.Fields(line)
+		key := apiKey{key: items[0], name: items[0], rate: defaultRate, burst: defaultBurst}
+		if len(items) > 1 {
+			key.name = items[1]
+		}
+		if len(items) > 2 {
+			if key.rate, err = strconv.ParseFloat(items[2], 64); err != nil || key.rate < 0 {
+				return nil, fmt.Errorf("%s: line %d: invalid rate: %s", file, n, items[2])
+			}
+		}
+		if len(items) > 3 {
+			if key.burst, err = strconv.Atoi(items[3]); err != nil || key.burst < 0 {
+				return nil, fmt.Errorf("%s: line %d: invalid burst: %s", file, n, items[3])
+			}
+		}
+		if _, ok := seen[key.key]; ok {
+			return nil, fmt.Errorf("%s: line %d: duplicated key of %s", file, n, key.name)
+		}
+		seen[key.key] = struct{}{}
+		keys = append(keys, key)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Limiter authenticates API keys and limits request rates of
+// API keys and client IPs, and batch sizes of query APIs
+type Limiter struct {
+	keys     []apiKey // nil for no authentication
+	keyRates map[string]*rate.Limiter
+
+	ipRate  rate.Limit
+	ipBurst int
+	mu      sync.Mutex
+	ipRates map[string]*ipLimiter
+
+	maxBatch map[string]int
+}
+
+type ipLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// ipLimiterTTL is the idle time after which limiter of a client IP is dropped
+const ipLimiterTTL = 10 * time.Minute
+
+// NewLimiter creates a Limiter from options
+func NewLimiter(opts LimitOptions) (*Limiter, error) {
+	l := &Limiter{
+		ipRate:   limit(opts.IPRate),
+		ipBurst:  burst(opts.IPRate, opts.IPBurst),
+		ipRates:  make(map[string]*ipLimiter),
+		maxBatch: make(map[string]int),
+	}
+
+	if opts.KeysFile != "" {
+		keys, err := readAPIKeys(opts.KeysFile, opts.KeyRate, opts.KeyBurst)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("no API keys found in file: %s", opts.KeysFile)
+		}
+		l.keys = keys
+		l.keyRates = make(map[string]*rate.Limiter, len(keys))
+		for _, key := range keys {
+			l.keyRates[key.key] = rate.NewLimiter(limit(key.rate), burst(key.rate, key.burst))
+		}
+	}
+
+	for endpoint, n := range opts.MaxBatch {
+		path := "/" + strings.TrimPrefix(endpoint, "/")
+		if !isQueryPath(path) {
+			return nil, fmt.Errorf("unknown endpoint for max batch size: %s", endpoint)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("invalid max batch size of %s: %d", endpoint, n)
+		}
+		// the same limit for legacy and versioned APIs
+		path = strings.TrimPrefix(path, "/"+APIVersion)
+		l.maxBatch[path] = n
+		l.maxBatch["/"+APIVersion+path] = n
+	}
+	return l, nil
+}
+
+// limit converts requests per second to rate.Limit, 0 for no limit
+func limit(r float64) rate.Limit {
+	if r <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(r)
+}
+
+// burst defaults to ceil of rate, and at least 1
+func burst(r float64, b int) int {
+	if b > 0 {
+		return b
+	}
+	if b = int(math.Ceil(r)); b < 1 {
+		b = 1
+	}
+	return b
+}
+
+// Middleware returns gin middleware enforcing the limits on query APIs,
+// other APIs like /healthz and /metrics are not limited
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isQueryPath(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		if err := l.allow(c.ClientIP(), c.GetHeader(APIKeyHeader)); err != nil {
+			if err.Code == CodeRateLimited {
+				c.Header("Retry-After", "1")
+			}
+			respondError(c, newErrorMessage(c), err)
+			c.Abort()
+			return
+		}
+
+		if n := l.maxBatch[c.FullPath()]; n > 0 {
+			c.Set(maxBatchSizeKey, n)
+		}
+		c.Next()
+	}
+}
+
+// allow checks API key, and rate limits of client IP and API key
+func (l *Limiter) allow(ip string, givenKey string) *APIError {
+	if !l.allowIP(ip) {
+		return errRateLimited
+	}
+	if l.keys == nil {
+		return nil
+	}
+	key, ok := l.findKey(givenKey)
+	if !ok {
+		return &APIError{Code: CodeUnauthorized, Message: "invalid or missing API key"}
+	}
+	if !l.keyRates[key.key].Allow() {
+		log.Warningf("rate limit exceeded for API key of %s", key.name)
+		return errRateLimited
+	}
+	return nil
+}
+
+var errRateLimited = &APIError{Code: CodeRateLimited, Message: "too many requests, please retry later"}
+
+// findKey checks given key in constant time
+func (l *Limiter) findKey(given string) (apiKey, bool) {
+	if given == "" {
+		return apiKey{}, false
+	}
+	for _, key := range l.keys {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(key.key)) == 1 {
+			return key, true
+		}
+	}
+	return apiKey{}, false
+}
+
+func (l *Limiter) allowIP(ip string) bool {
+	if l.ipRate == rate.Inf {
+		return true
+	}
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	il, ok := l.ipRates[ip]
+	if !ok {
+		// drop idle limiters before adding new one, so that the map
+		// does not grow with number of clients ever seen
+		if len(l.ipRates) >= 1024 {
+			for k, v := range l.ipRates {
+				if now.Sub(v.lastSeen) > ipLimiterTTL {
+					delete(l.ipRates, k)
+				}
+			}
+		}
+		il = &ipLimiter{limiter: rate.NewLimiter(l.ipRate, l.ipBurst)}
+		l.ipRates[ip] = il
+	}
+	il.lastSeen = now
+	return il.limiter.AllowN(now, 1)
+}
+
+// isQueryPath tells whether path is one of query APIs
+func isQueryPath(path string) bool {
+	for _, r := range routes {
+		if r.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+const maxBatchSizeKey = "gtaxon.maxBatchSize"
+
+// checkBatchSize returns error if number of queries exceeds the max batch
+// size of the endpoint set by Limiter
+func checkBatchSize(c *gin.Context, n int) error {
+	return batchSizeError(n, c.GetInt(maxBatchSizeKey))
+}
+
+func batchSizeError(n int, max int) error {
+	if max > 0 && n > max {
+		return &APIError{
+			Code:    CodeBatchTooLarge,
+			Message: fmt.Sprintf("too many queries: %d > %d, please split into smaller batches", n, max),
+		}
+	}
+	return nil
+}
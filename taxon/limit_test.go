@@ -0,0 +1,175 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeKeysFile writes content to a keys file in temporary directory
+func writeKeysFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestReadAPIKeys(t *testing.T) {
+	file := writeKeysFile(t, "# key name rate burst\n\nsecret1\nsecret2 team2\nsecret3\tteam3\t0.5\t3\n")
+	keys, err := readAPIKeys(file, 10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []apiKey{
+		{key: "secret1", name: "secret1", rate: 10, burst: 20},
+		{key: "secret2", name: "team2", rate: 10, burst: 20},
+		{key: "secret3", name: "team3", rate: 0.5, burst: 3},
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(keys), len(want))
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("key %d: got %+v, want %+v", i, keys[i], want[i])
+		}
+	}
+
+	for _, content := range []string{
+		"secret1 team1 fast\n",
+		"secret1 team1 1 -1\n",
+		"secret1 team1\nsecret1 team2\n",
+	} {
+		if _, err = readAPIKeys(writeKeysFile(t, content), 0, 0); err == nil {
+			t.Errorf("no error for keys file: %q", content)
+		}
+	}
+}
+
+func TestLimiterKeys(t *testing.T) {
+	l, err := NewLimiter(LimitOptions{KeysFile: writeKeysFile(t, "secret1 team1 1 2\nsecret2 team2\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"", "wrong", "secret"} {
+		if e := l.allow("10.0.0.1", key); e == nil || e.Code != CodeUnauthorized {
+			t.Errorf("key %q: got %v, want %s", key, e, CodeUnauthorized)
+		}
+	}
+
+	// burst of 2, then limited
+	for i := 0; i < 2; i++ {
+		if e := l.allow("10.0.0.1", "secret1"); e != nil {
+			t.Fatalf("request %d of secret1: %s", i+1, e)
+		}
+	}
+	if e := l.allow("10.0.0.2", "secret1"); e == nil || e.Code != CodeRateLimited {
+		t.Errorf("request 3 of secret1: got %v, want %s", e, CodeRateLimited)
+	}
+
+	// no limit for keys without rate
+	for i := 0; i < 100; i++ {
+		if e := l.allow("10.0.0.1", "secret2"); e != nil {
+			t.Fatalf("request %d of secret2: %s", i+1, e)
+		}
+	}
+
+	if _, err = NewLimiter(LimitOptions{KeysFile: writeKeysFile(t, "# no keys\n")}); err == nil {
+		t.Error("no error for empty keys file")
+	}
+}
+
+func TestLimiterIPRate(t *testing.T) {
+	l, err := NewLimiter(LimitOptions{IPRate: 0.1, IPBurst: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if e := l.allow("10.0.0.1", ""); e != nil {
+			t.Fatalf("request %d: %s", i+1, e)
+		}
+	}
+	if e := l.allow("10.0.0.1", ""); e == nil || e.Code != CodeRateLimited {
+		t.Errorf("request 4: got %v, want %s", e, CodeRateLimited)
+	}
+	if e := l.allow("10.0.0.2", ""); e != nil {
+		t.Errorf("request of another IP: %s", e)
+	}
+}
+
+func TestBurst(t *testing.T) {
+	for _, test := range []struct {
+		rate  float64
+		burst int
+		want  int
+	}{
+		{0, 0, 1},
+		{0.5, 0, 1},
+		{2.5, 0, 3},
+		{2.5, 10, 10},
+	} {
+		if got := burst(test.rate, test.burst); got != test.want {
+			t.Errorf("burst(%v, %d): got %d, want %d", test.rate, test.burst, got, test.want)
+		}
+	}
+}
+
+func TestLimiterMaxBatch(t *testing.T) {
+	for _, endpoint := range []string{"unknown", "/v1/unknown"} {
+		if _, err := NewLimiter(LimitOptions{MaxBatch: map[string]int{endpoint: 1}}); err == nil {
+			t.Errorf("no error for endpoint: %s", endpoint)
+		}
+	}
+	if _, err := NewLimiter(LimitOptions{MaxBatch: map[string]int{"lca": -1}}); err == nil {
+		t.Error("no error for negative max batch size")
+	}
+
+	l, err := NewLimiter(LimitOptions{MaxBatch: map[string]int{"taxid2taxon": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(l.Middleware())
+	newTestTaxonomy().RegisterRoutes(router)
+
+	for _, test := range []struct {
+		path string
+		want int
+	}{
+		{"/v1/taxid2taxon?taxid=9606&taxid=9598", http.StatusOK},
+		{"/v1/taxid2taxon?taxid=9606&taxid=9598&taxid=1", http.StatusRequestEntityTooLarge},
+		{"/taxid2taxon?taxid=9606&taxid=9598&taxid=1", http.StatusRequestEntityTooLarge},
+		{"/v1/lca?taxids=9606,9598&taxids=9606,562&taxids=1,2", http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.want {
+			t.Errorf("%s: got status %d, want %d: %s", test.path, w.Code, test.want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics are registered to a private registry,
+// so that importing this package does not touch the global one
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gtaxon_http_requests_total",
+		Help: "Number of HTTP requests by endpoint, method and status code.",
+	}, []string{"path", "method", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gtaxon_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by endpoint, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"path", "method", "status"})
+
+	batchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gtaxon_batch_size",
+		Help:    "Number of queries in one request by endpoint.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10), // 1 ~ 262144
+	}, []string{"path"})
+
+	dbPoolWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gtaxon_dbpool_wait_seconds",
+		Help:    "Time spent waiting for a database connection from DBPool.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10), // 0.1ms ~ 26s
+	})
+
+	dbPoolCheckedOut = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gtaxon_dbpool_checked_out",
+		Help: "Number of database connections checked out from DBPool.",
+	})
+
+	taxonomyRecords = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "gtaxon_taxonomy_records",
+		Help: "Number of records of in-memory taxonomy datasets being served.",
+	}, []string{"dataset"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		batchSize,
+		dbPoolWaitSeconds,
+		dbPoolCheckedOut,
+		taxonomyRecords,
+	)
+}
+
+// MetricsMiddleware returns gin middleware collecting request counts,
+// latencies and batch sizes
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		path := c.FullPath() // route pattern, avoid high cardinality
+		if path == "" {
+			path = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequestsTotal.WithLabelValues(path, c.Request.Method, status).Inc()
+		httpRequestDuration.WithLabelValues(path, c.Request.Method, status).
+			Observe(time.Since(start).Seconds())
+		if n, ok := c.Get(batchSizeKey); ok {
+			batchSize.WithLabelValues(path).Observe(float64(n.(int)))
+		}
+	}
+}
+
+// MetricsHandler returns handler of metrics in Prometheus text format
+func MetricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+}
+
+const batchSizeKey = "gtaxon.batch_size"
+
+// setBatchSize records number of queries of the request for metrics
+func setBatchSize(c *gin.Context, n int) {
+	c.Set(batchSizeKey, n)
+}
+
+// observeTaxonomy records sizes of Taxonomy being served
+func observeTaxonomy(t *Taxonomy) {
+	taxonomyRecords.WithLabelValues("nodes").Set(float64(len(t.Nodes)))
+	taxonomyRecords.WithLabelValues("names").Set(float64(len(t.Names)))
+	taxonomyRecords.WithLabelValues("divisions").Set(float64(len(t.Divisions)))
+	taxonomyRecords.WithLabelValues("gencodes").Set(float64(len(t.GenCodes)))
+	taxonomyRecords.WithLabelValues("name_index").Set(float64(len(t.NameIndex)))
+}
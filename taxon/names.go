@@ -21,9 +21,12 @@
 package taxon
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 
@@ -33,14 +36,17 @@ import (
 )
 
 // ImportNames reads data from names.dmp and write to bolt database
-func ImportNames(dbFile string, bucket string, dataFile string, chunkSize int, force bool) {
+func ImportNames(dbFile string, bucket string, dataFile string, chunkSize int, force bool) error {
 	db, err := bolt.Open(dbFile, 0600, nil)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 	defer db.Close()
 
 	if force {
-		err = deleteBucket(db, bucket)
-		checkError(err)
+		if err = deleteBucket(db, bucket); err != nil {
+			return err
+		}
 		log.Info("Old database deleted: %s", bucket)
 	}
 
@@ -62,14 +68,15 @@ func ImportNames(dbFile string, bucket string, dataFile string, chunkSize int, f
 	}
 
 	reader, err := breader.NewBufferedReader(dataFile, runtime.NumCPU(), chunkSize, fn)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 
 	names := make(map[string]nodes.Name)
 	n := 0
 	for chunk := range reader.Ch {
 		if chunk.Err != nil {
-			checkError(chunk.Err)
-			return
+			return chunk.Err
 		}
 
 		for _, data := range chunk.Data {
@@ -88,21 +95,30 @@ func ImportNames(dbFile string, bucket string, dataFile string, chunkSize int, f
 
 	// write to db
 	chDone := make(chan int)
+	var errWrite error
 	go func() {
 		records := make([][]string, chunkSize)
 		i := 0
 		n := 0
 		for s := range chResults {
+			if errWrite != nil { // just drain the channel
+				continue
+			}
 			records[i] = s
 			i++
 			n++
 			if i%chunkSize == 0 {
-				write2db(records, db, bucket)
+				if errWrite = write2db(records, db, bucket); errWrite != nil {
+					continue
+				}
 				log.Info("%d records imported to %s", n, dbFile)
 				records = make([][]string, chunkSize)
 				i = 0
 			}
 		}
+		if errWrite == nil && i > 0 {
+			errWrite = write2db(records[:i], db, bucket)
+		}
 		log.Info("%d records imported to %s", n, dbFile)
 		chDone <- 1
 	}()
@@ -110,6 +126,8 @@ func ImportNames(dbFile string, bucket string, dataFile string, chunkSize int, f
 	// name to json
 	tokens := make(chan int, runtime.NumCPU())
 	var wg sync.WaitGroup
+	var errJSON error
+	var mutex sync.Mutex
 	for _, name := range names {
 		tokens <- 1
 		wg.Add(1)
@@ -119,13 +137,22 @@ func ImportNames(dbFile string, bucket string, dataFile string, chunkSize int, f
 				<-tokens
 			}()
 			nameJSONStr, err := name.ToJSON()
-			checkError(err)
+			if err != nil {
+				mutex.Lock()
+				errJSON = err
+				mutex.Unlock()
+				return
+			}
 			chResults <- []string{name.TaxID, nameJSONStr}
 		}(name)
 	}
 	wg.Wait()
 	close(chResults)
 	<-chDone
+	if errJSON != nil {
+		return errJSON
+	}
+	return errWrite
 }
 
 // QueryNameByTaxID querys Name by taxid
@@ -137,7 +164,7 @@ func QueryNameByTaxID(db *bolt.DB, bucket string, taxids []string) ([]nodes.Name
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 		for i, taxid := range taxids {
 			s := string(b.Get([]byte(taxid)))
@@ -146,7 +173,9 @@ func QueryNameByTaxID(db *bolt.DB, bucket string, taxids []string) ([]nodes.Name
 				continue
 			}
 			name, err := nodes.NameFromJSON(s)
-			checkError(err)
+			if err != nil {
+				return errors.New("failed to parse name record from database")
+			}
 			names[i] = name
 		}
 		return nil
@@ -160,10 +189,17 @@ func LoadAllNames(db *bolt.DB, bucket string) (map[string]nodes.Name, error) {
 
 	ch := make(chan string, runtime.NumCPU())
 	chDone := make(chan int)
+	var errParse error
 	go func() {
 		for s := range ch {
+			if errParse != nil { // just drain the channel
+				continue
+			}
 			name, err := nodes.NameFromJSON(s)
-			checkError(err)
+			if err != nil {
+				errParse = fmt.Errorf("failed to parse name record from database: %s", err)
+				continue
+			}
 			names[name.TaxID] = name
 		}
 		chDone <- 1
@@ -172,39 +208,40 @@ func LoadAllNames(db *bolt.DB, bucket string) (map[string]nodes.Name, error) {
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 
-		b.ForEach(func(k, v []byte) error {
+		return b.ForEach(func(k, v []byte) error {
 			ch <- string(v)
 			return nil
 		})
-
-		return nil
 	})
 	close(ch)
 	<-chDone
-	return names, err
+	if err != nil {
+		return nil, err
+	}
+	if errParse != nil {
+		return nil, errParse
+	}
+	return names, nil
 }
 
-// QueryTaxIDByName query taxid by name
-func QueryTaxIDByName(db *bolt.DB, bucket string, useRegexp bool, nameClass string, threads int, queries []string) (map[string][]string, error) {
-	if nodes.Names == nil {
-		log.Info("load all names ...")
-		names, err := LoadAllNames(db, bucket)
-		if err != nil {
-			return nil, err
-		}
-		nodes.SetNames(names)
-		log.Info("load all names ... done")
+// QueryTaxIDByName query taxid by name. Names are matched exactly
+// with help of NameIndex, or by regular expressions.
+// Searching by regular expressions stops when ctx is done.
+func (t *Taxonomy) QueryTaxIDByName(ctx context.Context, useRegexp bool, nameClass string, threads int, queries []string) (map[string][]string, error) {
+	if !useRegexp {
+		return t.queryTaxIDByExactName(nameClass, queries), nil
 	}
 
-	var queryRegexps map[string]*regexp.Regexp
-	if useRegexp {
-		queryRegexps = make(map[string]*regexp.Regexp, len(queries))
-		for _, query := range queries {
-			queryRegexps[query] = regexp.MustCompile(query)
+	queryRegexps := make(map[string]*regexp.Regexp, len(queries))
+	for _, query := range queries {
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, invalidQuery(err.Error(), query)
 		}
+		queryRegexps[query] = re
 	}
 
 	result := make(map[string][]string)
@@ -228,7 +265,10 @@ func QueryTaxIDByName(db *bolt.DB, bucket string, useRegexp bool, nameClass stri
 	if nameClass != "" {
 		limitNameClass = true
 	}
-	for _, name := range nodes.Names {
+	for _, name := range t.Names {
+		if ctx.Err() != nil {
+			break
+		}
 		wg.Add(1)
 		tokens <- 1
 		go func(name nodes.Name) {
@@ -237,23 +277,12 @@ func QueryTaxIDByName(db *bolt.DB, bucket string, useRegexp bool, nameClass stri
 				<-tokens
 			}()
 
-			for _, query := range queries {
+			for query, re := range queryRegexps {
 				for _, nameItem := range name.Names {
 					if limitNameClass && nameItem.NameClass != nameClass {
 						continue
 					}
-					match := false
-					if useRegexp {
-						if queryRegexps[query].MatchString(nameItem.Name) {
-							match = true
-						}
-					} else {
-						if query == nameItem.Name {
-							match = true
-						}
-					}
-
-					if match {
+					if re.MatchString(nameItem.Name) {
 						chResult <- []string{query, name.TaxID}
 						break
 					}
@@ -265,5 +294,76 @@ func QueryTaxIDByName(db *bolt.DB, bucket string, useRegexp bool, nameClass stri
 	close(chResult)
 	<-chDone
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+func (t *Taxonomy) queryTaxIDByExactName(nameClass string, queries []string) map[string][]string {
+	result := make(map[string][]string)
+	for _, query := range queries {
+		if _, ok := result[query]; ok { // duplicated query
+			continue
+		}
+		for _, taxid := range t.NameIndex.TaxIDs(query) {
+			for _, nameItem := range t.Names[taxid].Names {
+				if nameClass != "" && nameItem.NameClass != nameClass {
+					continue
+				}
+				if query == nameItem.Name {
+					result[query] = append(result[query], taxid)
+					break
+				}
+			}
+		}
+	}
+	return result
+}
+
+// NameIndex maps lower-cased names of all name classes to taxids
+type NameIndex map[string][]string
+
+// NewNameIndex builds NameIndex from names
+func NewNameIndex(names map[string]nodes.Name) NameIndex {
+	idx := make(NameIndex, len(names))
+	for taxid, name := range names {
+		for _, nameItem := range name.Names {
+			key := strings.ToLower(nameItem.Name)
+			if existed, ok := idx[key]; ok {
+				if existed[len(existed)-1] == taxid { // different name classes
+					continue
+				}
+				idx[key] = append(existed, taxid)
+			} else {
+				idx[key] = []string{taxid}
+			}
+		}
+	}
+	for _, taxids := range idx {
+		if len(taxids) > 1 {
+			sort.Strings(taxids)
+		}
+	}
+	return idx
+}
+
+// TaxIDs returns taxids of a name, case-insensitively
+func (idx NameIndex) TaxIDs(name string) []string {
+	return idx[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// Suggest returns names for an unresolved name by removing trailing words
+// one by one, e.g., "Escherichia coli K-12 xyz" -> "Escherichia coli K-12",
+// "Escherichia coli", "Escherichia"
+func (idx NameIndex) Suggest(name string) []string {
+	words := strings.Fields(name)
+	suggestions := []string{}
+	for n := len(words) - 1; n > 0; n-- {
+		candidate := strings.Join(words[:n], " ")
+		if _, ok := idx[strings.ToLower(candidate)]; ok {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return suggestions
+}
@@ -33,14 +33,17 @@ import (
 )
 
 // ImportNodes reads data from nodes.dmp and write to bolt database
-func ImportNodes(dbFile string, bucket string, dataFile string, batchSize int, force bool) {
+func ImportNodes(dbFile string, bucket string, dataFile string, batchSize int, force bool) error {
 	db, err := bolt.Open(dbFile, 0600, nil)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 	defer db.Close()
 
 	if force {
-		err = deleteBucket(db, bucket)
-		checkError(err)
+		if err = deleteBucket(db, bucket); err != nil {
+			return err
+		}
 		log.Info("Old database deleted: %s", bucket)
 	}
 
@@ -63,13 +66,14 @@ func ImportNodes(dbFile string, bucket string, dataFile string, batchSize int, f
 	}
 
 	reader, err := breader.NewBufferedReader(dataFile, runtime.NumCPU(), batchSize, fn)
-	checkError(err)
+	if err != nil {
+		return err
+	}
 
 	n := 0
 	for chunk := range reader.Ch {
 		if chunk.Err != nil {
-			checkError(chunk.Err)
-			return
+			return chunk.Err
 		}
 
 		records := make([][]string, len(chunk.Data))
@@ -77,15 +81,17 @@ func ImportNodes(dbFile string, bucket string, dataFile string, batchSize int, f
 			node := data.(nodes.Node)
 			nodeJSONStr, err := node.ToJSON()
 			if err != nil {
-				checkError(chunk.Err)
-				return
+				return err
 			}
 			records[i] = []string{node.TaxID, nodeJSONStr}
 		}
-		write2db(records, db, bucket)
+		if err = write2db(records, db, bucket); err != nil {
+			return err
+		}
 		n += len(records)
 		log.Info("%d records imported to %s", n, dbFile)
 	}
+	return nil
 }
 
 var reDigitals = regexp.MustCompile(`^\d+$`)
@@ -94,7 +100,7 @@ var reDigitals = regexp.MustCompile(`^\d+$`)
 func QueryNodeByTaxID(db *bolt.DB, bucket string, taxids []string) ([]nodes.Node, error) {
 	for _, taxid := range taxids {
 		if !reDigitals.MatchString(taxid) {
-			return []nodes.Node{}, fmt.Errorf("non-digital taxid given: %s", taxid)
+			return []nodes.Node{}, invalidQuery("non-digital taxid given: "+taxid, taxid)
 		}
 	}
 	nods := make([]nodes.Node, len(taxids))
@@ -104,7 +110,7 @@ func QueryNodeByTaxID(db *bolt.DB, bucket string, taxids []string) ([]nodes.Node
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 		for i, taxid := range taxids {
 			s := string(b.Get([]byte(taxid)))
@@ -129,10 +135,17 @@ func LoadAllNodes(db *bolt.DB, bucket string) (map[string]nodes.Node, error) {
 
 	ch := make(chan string, runtime.NumCPU())
 	chDone := make(chan int)
+	var errParse error
 	go func() {
 		for s := range ch {
+			if errParse != nil { // just drain the channel
+				continue
+			}
 			node, err := nodes.NodeFromJSON(s)
-			checkError(err)
+			if err != nil {
+				errParse = fmt.Errorf("failed to parse node record from database: %s", err)
+				continue
+			}
 			nods[node.TaxID] = node
 		}
 		chDone <- 1
@@ -141,17 +154,21 @@ func LoadAllNodes(db *bolt.DB, bucket string) (map[string]nodes.Node, error) {
 	err := db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucket))
 		if b == nil {
-			return fmt.Errorf("database not exists: %s", bucket)
+			return fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
 		}
 
-		b.ForEach(func(k, v []byte) error {
+		return b.ForEach(func(k, v []byte) error {
 			ch <- string(v)
 			return nil
 		})
-
-		return nil
 	})
 	close(ch)
 	<-chDone
-	return nods, err
+	if err != nil {
+		return nil, err
+	}
+	if errParse != nil {
+		return nil, errParse
+	}
+	return nods, nil
 }
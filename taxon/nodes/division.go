@@ -21,10 +21,7 @@
 //Package nodes a
 package nodes
 
-import (
-	"encoding/json"
-	"sync"
-)
+import "encoding/json"
 
 // Division defines the NCBI taxonomy division
 type Division struct {
@@ -59,15 +56,3 @@ func DivisionFromArgs(items []string) Division {
 		Comments:     items[3],
 	}
 }
-
-// Divisions is a map storing all divisions
-var Divisions map[string]Division
-
-var mutex3 = &sync.Mutex{}
-
-// SetDivisions sets Nodes
-func SetDivisions(divisions map[string]Division) {
-	mutex3.Lock()
-	Divisions = divisions
-	mutex3.Unlock()
-}
@@ -21,10 +21,7 @@
 //Package nodes a
 package nodes
 
-import (
-	"encoding/json"
-	"sync"
-)
+import "encoding/json"
 
 // GenCode defines the NCBI taxonomy division
 type GenCode struct {
@@ -61,15 +58,3 @@ func GenCodeFromArgs(items []string) GenCode {
 		StartCodons:      items[4],
 	}
 }
-
-// GenCodes is a map storing all divisions
-var GenCodes map[string]GenCode
-
-var mutex4 = &sync.Mutex{}
-
-// SetGenCodes sets GenCodes
-func SetGenCodes(divisions map[string]GenCode) {
-	mutex4.Lock()
-	GenCodes = divisions
-	mutex4.Unlock()
-}
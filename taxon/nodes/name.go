@@ -21,10 +21,7 @@
 //Package nodes a
 package nodes
 
-import (
-	"encoding/json"
-	"sync"
-)
+import "encoding/json"
 
 // Name includes all names for a taxid
 type Name struct {
@@ -45,6 +42,16 @@ func (name Name) ToJSON() (string, error) {
 	return string(s), err
 }
 
+// ScientificName returns the name of class "scientific name"
+func (name Name) ScientificName() string {
+	for _, nameItem := range name.Names {
+		if nameItem.NameClass == "scientific name" {
+			return nameItem.Name
+		}
+	}
+	return ""
+}
+
 // NameFromJSON return Name object from JSON string
 func NameFromJSON(s string) (Name, error) {
 	var name Name
@@ -85,15 +92,3 @@ func MergeNames(names ...Name) Name {
 	}
 	return name
 }
-
-// Names is a map storing all names
-var Names map[string]Name
-
-var mutex1 = &sync.Mutex{}
-
-// SetNames sets Names
-func SetNames(names map[string]Name) {
-	mutex1.Lock()
-	Names = names
-	mutex1.Unlock()
-}
@@ -24,7 +24,6 @@ package nodes
 import (
 	"encoding/json"
 	"errors"
-	"sync"
 
 	"github.com/shenwei356/util/stringutil"
 )
@@ -115,21 +114,9 @@ func NodeFromArgs(items []string) Node {
 	}
 }
 
-// Nodes is a map storing all nodes
-var Nodes map[string]Node
-
-var mutex2 = &sync.Mutex{}
-
-// SetNodes sets Nodes
-func SetNodes(nodes map[string]Node) {
-	mutex2.Lock()
-	Nodes = nodes
-	mutex2.Unlock()
-}
-
 // LCA return the lowest common ancestor for a list of taxids
 func LCA(nodes map[string]Node, taxids []string) (Node, error) {
-	if Nodes == nil {
+	if nodes == nil {
 		return Node{}, errors.New("nodes is nil")
 	}
 	if len(taxids) < 2 {
@@ -143,10 +130,13 @@ func LCA(nodes map[string]Node, taxids []string) (Node, error) {
 		}
 		currents[taxid] = nodes[taxid]
 	}
+	if len(currents) == 0 {
+		return Node{}, errors.New("no valid taxids given")
+	}
 
 	allAncestors := [][]Node{}
 	for _, node := range currents {
-		allAncestors = append(allAncestors, ancestorsOfNode(nodes, node))
+		allAncestors = append(allAncestors, Ancestors(nodes, node))
 	}
 
 	commonAncestors := make(map[string]int)
@@ -173,16 +163,22 @@ func LCA(nodes map[string]Node, taxids []string) (Node, error) {
 	}
 
 	sorted := stringutil.SortCountOfString(commonAncestors, false)
+	if len(sorted) == 0 { // broken lineages
+		return Node{}, errors.New("no common ancestor found")
+	}
 	return nodes[sorted[0].Key], nil
 }
 
-// ancestorsOfNode result including root node
-func ancestorsOfNode(nodes map[string]Node, node Node) []Node {
+// Ancestors returns the node and all its ancestors, including root node
+func Ancestors(nodes map[string]Node, node Node) []Node {
 	current := node
 	parrent := nodes[current.PTaxID]
 	ancestors := []Node{current}
 
 	for parrent.TaxID != "1" {
+		if parrent.TaxID == "" { // missing node, broken lineage
+			return ancestors
+		}
 		ancestors = append(ancestors, parrent)
 		current = parrent
 		parrent = nodes[current.PTaxID]
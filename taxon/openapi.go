@@ -0,0 +1,252 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"bytes"
+	_ "embed" // for openapi.json
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the OpenAPI 3 document of REST APIs
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// openAPIDoc holds parts of OpenAPI document for checking and rendering
+type openAPIDoc struct {
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description"`
+	Parameters  json.RawMessage            `json:"parameters"`
+	RequestBody json.RawMessage            `json:"requestBody"`
+	Responses   map[string]json.RawMessage `json:"responses"`
+	Deprecated  bool                       `json:"deprecated"`
+
+	// Optional operations are not always registered,
+	// e.g., admin APIs are only registered with admin token
+	Optional bool `json:"x-gtaxon-optional"`
+}
+
+func parseOpenAPI() (*openAPIDoc, error) {
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %s", err)
+	}
+	return &doc, nil
+}
+
+// CheckOpenAPI checks that every route is described in OpenAPI document,
+// and every operation in the document is registered, except optional ones.
+// It's checked by tests, and StartServer warns of mismatches.
+func CheckOpenAPI(routes gin.RoutesInfo) error {
+	doc, err := parseOpenAPI()
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	registered := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		path := openAPIPath(r.Path)
+		registered[r.Method+" "+path] = true
+		if _, ok := doc.Paths[path][strings.ToLower(r.Method)]; !ok {
+			problems = append(problems, fmt.Sprintf("route not documented: %s %s", r.Method, r.Path))
+		}
+	}
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			if !op.Optional && !registered[strings.ToUpper(method)+" "+path] {
+				problems = append(problems, fmt.Sprintf("operation not registered: %s %s", strings.ToUpper(method), path))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("OpenAPI document does not match routes:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// reGinParam matches path parameters of gin, e.g., ":id" and "*filepath"
+var reGinParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// openAPIPath converts gin path to OpenAPI path, e.g., "/ui/*filepath" to "/ui/{filepath}"
+func openAPIPath(path string) string {
+	return reGinParam.ReplaceAllString(path, "{$1}")
+}
+
+// RegisterDocRoutes registers /openapi.json and /docs to router
+func RegisterDocRoutes(router gin.IRoutes) error {
+	page, err := renderDocs()
+	if err != nil {
+		return err
+	}
+	router.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+	})
+	router.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	})
+	return nil
+}
+
+// order of methods in docs page
+var docMethods = []string{"get", "post", "put", "patch", "delete"}
+
+type docOperation struct {
+	Method string
+	Path   string
+	openAPIOperation
+}
+
+type docSchema struct {
+	Name   string
+	Schema string
+}
+
+// renderDocs renders OpenAPI document to a standalone HTML page,
+// so that it could be browsed without internet access
+func renderDocs() ([]byte, error) {
+	doc, err := parseOpenAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var operations []docOperation
+	for _, path := range paths {
+		for _, method := range docMethods {
+			if op, ok := doc.Paths[path][method]; ok {
+				operations = append(operations, docOperation{strings.ToUpper(method), path, op})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	schemas := make([]docSchema, len(names))
+	for i, name := range names {
+		schemas[i] = docSchema{name, indentJSON(doc.Components.Schemas[name])}
+	}
+
+	var buf bytes.Buffer
+	err = docsTemplate.Execute(&buf, map[string]interface{}{
+		"Doc":        doc,
+		"Operations": operations,
+		"Schemas":    schemas,
+	})
+	return buf.Bytes(), err
+}
+
+func indentJSON(data json.RawMessage) string {
+	if len(data) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"json":  indentJSON,
+	"lower": strings.ToLower,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Doc.Info.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 1em; color: #222; }
+.op { border: 1px solid #ddd; border-radius: 4px; margin: 1em 0; padding: 0.5em 1em; }
+.method { display: inline-block; min-width: 4em; font-weight: bold; color: #fff; text-align: center; border-radius: 3px; }
+.get { background: #2b7bb9; } .post { background: #2e8b57; }
+.deprecated { color: #b00; font-size: 0.8em; }
+code, pre { background: #f6f8fa; }
+pre { padding: 0.5em; overflow-x: auto; }
+details summary { cursor: pointer; }
+</style>
+</head>
+<body>
+<h1>{{.Doc.Info.Title}} <small>{{.Doc.Info.Version}}</small></h1>
+<p>{{.Doc.Info.Description}}</p>
+<p>Machine-readable document: <a href="openapi.json">openapi.json</a></p>
+
+<h2>Endpoints</h2>
+<ul>
+{{- range .Operations}}
+<li><a href="#{{lower .Method}}{{.Path}}">{{.Method}} {{.Path}}</a> {{.Summary}}{{if .Deprecated}} <span class="deprecated">deprecated</span>{{end}}</li>
+{{- end}}
+</ul>
+
+{{range .Operations}}
+<div class="op" id="{{lower .Method}}{{.Path}}">
+<h3><span class="method {{lower .Method}}">{{.Method}}</span> <code>{{.Path}}</code> {{.Summary}}{{if .Deprecated}} <span class="deprecated">deprecated</span>{{end}}</h3>
+<p>{{.Description}}</p>
+{{- if .Parameters}}
+<details><summary>Parameters</summary><pre>{{json .Parameters}}</pre></details>
+{{- end}}
+{{- if .RequestBody}}
+<details><summary>Request body</summary><pre>{{json .RequestBody}}</pre></details>
+{{- end}}
+<details><summary>Responses</summary>
+{{- range $code, $resp := .Responses}}
+<p><b>{{$code}}</b></p><pre>{{json $resp}}</pre>
+{{- end}}
+</details>
+</div>
+{{end}}
+
+<h2>Schemas</h2>
+{{range .Schemas}}
+<details id="{{.Name}}"><summary><code>{{.Name}}</code></summary><pre>{{.Schema}}</pre></details>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,1445 @@
+// gRPC service of gtaxon server.
+//
+// Go code is generated by:
+//
+//     protoc --go_out=. --go_opt=paths=source_relative \
+//         --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//         taxon/pb/gtaxon.proto
+//
+// Results are in the same order of queries. Methods ending with "Stream"
+// send results in chunks as soon as they are resolved, suitable for large
+// batches. API key, if required by server, is sent in metadata "x-api-key".
+// Failures are reported with gRPC status carrying google.rpc.ErrorInfo,
+// whose reason is the stable error code of REST API, e.g., "invalid_query".
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: taxon/pb/gtaxon.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Molecule int32
+
+const (
+	Molecule_PROT Molecule = 0
+	Molecule_NUCL Molecule = 1
+)
+
+// Enum value maps for Molecule.
+var (
+	Molecule_name = map[int32]string{
+		0: "PROT",
+		1: "NUCL",
+	}
+	Molecule_value = map[string]int32{
+		"PROT": 0,
+		"NUCL": 1,
+	}
+)
+
+func (x Molecule) Enum() *Molecule {
+	p := new(Molecule)
+	*p = x
+	return p
+}
+
+func (x Molecule) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Molecule) Descriptor() protoreflect.EnumDescriptor {
+	return file_taxon_pb_gtaxon_proto_enumTypes[0].Descriptor()
+}
+
+func (Molecule) Type() protoreflect.EnumType {
+	return &file_taxon_pb_gtaxon_proto_enumTypes[0]
+}
+
+func (x Molecule) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Molecule.Descriptor instead.
+func (Molecule) EnumDescriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{0}
+}
+
+type Gi2TaxIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Molecule      Molecule               `protobuf:"varint,1,opt,name=molecule,proto3,enum=gtaxon.Molecule" json:"molecule,omitempty"`
+	Gis           []string               `protobuf:"bytes,2,rep,name=gis,proto3" json:"gis,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Gi2TaxIDRequest) Reset() {
+	*x = Gi2TaxIDRequest{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Gi2TaxIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Gi2TaxIDRequest) ProtoMessage() {}
+
+func (x *Gi2TaxIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Gi2TaxIDRequest.ProtoReflect.Descriptor instead.
+func (*Gi2TaxIDRequest) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Gi2TaxIDRequest) GetMolecule() Molecule {
+	if x != nil {
+		return x.Molecule
+	}
+	return Molecule_PROT
+}
+
+func (x *Gi2TaxIDRequest) GetGis() []string {
+	if x != nil {
+		return x.Gis
+	}
+	return nil
+}
+
+type Acc2TaxIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Molecule      Molecule               `protobuf:"varint,1,opt,name=molecule,proto3,enum=gtaxon.Molecule" json:"molecule,omitempty"`
+	Accessions    []string               `protobuf:"bytes,2,rep,name=accessions,proto3" json:"accessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Acc2TaxIDRequest) Reset() {
+	*x = Acc2TaxIDRequest{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Acc2TaxIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Acc2TaxIDRequest) ProtoMessage() {}
+
+func (x *Acc2TaxIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Acc2TaxIDRequest.ProtoReflect.Descriptor instead.
+func (*Acc2TaxIDRequest) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Acc2TaxIDRequest) GetMolecule() Molecule {
+	if x != nil {
+		return x.Molecule
+	}
+	return Molecule_PROT
+}
+
+func (x *Acc2TaxIDRequest) GetAccessions() []string {
+	if x != nil {
+		return x.Accessions
+	}
+	return nil
+}
+
+type TaxIDItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Taxid         int64                  `protobuf:"varint,2,opt,name=taxid,proto3" json:"taxid,omitempty"` // 0 if not found
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaxIDItem) Reset() {
+	*x = TaxIDItem{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaxIDItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaxIDItem) ProtoMessage() {}
+
+func (x *TaxIDItem) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaxIDItem.ProtoReflect.Descriptor instead.
+func (*TaxIDItem) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TaxIDItem) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *TaxIDItem) GetTaxid() int64 {
+	if x != nil {
+		return x.Taxid
+	}
+	return 0
+}
+
+type TaxIDResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*TaxIDItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaxIDResponse) Reset() {
+	*x = TaxIDResponse{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaxIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaxIDResponse) ProtoMessage() {}
+
+func (x *TaxIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaxIDResponse.ProtoReflect.Descriptor instead.
+func (*TaxIDResponse) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TaxIDResponse) GetItems() []*TaxIDItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type TaxID2TaxonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Taxids        []int64                `protobuf:"varint,1,rep,packed,name=taxids,proto3" json:"taxids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaxID2TaxonRequest) Reset() {
+	*x = TaxID2TaxonRequest{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaxID2TaxonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaxID2TaxonRequest) ProtoMessage() {}
+
+func (x *TaxID2TaxonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaxID2TaxonRequest.ProtoReflect.Descriptor instead.
+func (*TaxID2TaxonRequest) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TaxID2TaxonRequest) GetTaxids() []int64 {
+	if x != nil {
+		return x.Taxids
+	}
+	return nil
+}
+
+type TaxID2TaxonItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Taxid         int64                  `protobuf:"varint,1,opt,name=taxid,proto3" json:"taxid,omitempty"`
+	Taxon         *Taxon                 `protobuf:"bytes,2,opt,name=taxon,proto3" json:"taxon,omitempty"` // unset if not found
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaxID2TaxonItem) Reset() {
+	*x = TaxID2TaxonItem{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaxID2TaxonItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaxID2TaxonItem) ProtoMessage() {}
+
+func (x *TaxID2TaxonItem) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaxID2TaxonItem.ProtoReflect.Descriptor instead.
+func (*TaxID2TaxonItem) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TaxID2TaxonItem) GetTaxid() int64 {
+	if x != nil {
+		return x.Taxid
+	}
+	return 0
+}
+
+func (x *TaxID2TaxonItem) GetTaxon() *Taxon {
+	if x != nil {
+		return x.Taxon
+	}
+	return nil
+}
+
+type TaxID2TaxonResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*TaxID2TaxonItem     `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaxID2TaxonResponse) Reset() {
+	*x = TaxID2TaxonResponse{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaxID2TaxonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaxID2TaxonResponse) ProtoMessage() {}
+
+func (x *TaxID2TaxonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaxID2TaxonResponse.ProtoReflect.Descriptor instead.
+func (*TaxID2TaxonResponse) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TaxID2TaxonResponse) GetItems() []*TaxID2TaxonItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type Name2TaxIDRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	Regexp        bool                   `protobuf:"varint,2,opt,name=regexp,proto3" json:"regexp,omitempty"`                       // names are regular expressions
+	NameClass     string                 `protobuf:"bytes,3,opt,name=name_class,json=nameClass,proto3" json:"name_class,omitempty"` // e.g., "scientific name", all classes if empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Name2TaxIDRequest) Reset() {
+	*x = Name2TaxIDRequest{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Name2TaxIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Name2TaxIDRequest) ProtoMessage() {}
+
+func (x *Name2TaxIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Name2TaxIDRequest.ProtoReflect.Descriptor instead.
+func (*Name2TaxIDRequest) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Name2TaxIDRequest) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *Name2TaxIDRequest) GetRegexp() bool {
+	if x != nil {
+		return x.Regexp
+	}
+	return false
+}
+
+func (x *Name2TaxIDRequest) GetNameClass() string {
+	if x != nil {
+		return x.NameClass
+	}
+	return ""
+}
+
+type TaxIDName struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Taxid          int64                  `protobuf:"varint,1,opt,name=taxid,proto3" json:"taxid,omitempty"`
+	ScientificName string                 `protobuf:"bytes,2,opt,name=scientific_name,json=scientificName,proto3" json:"scientific_name,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TaxIDName) Reset() {
+	*x = TaxIDName{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaxIDName) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaxIDName) ProtoMessage() {}
+
+func (x *TaxIDName) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaxIDName.ProtoReflect.Descriptor instead.
+func (*TaxIDName) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TaxIDName) GetTaxid() int64 {
+	if x != nil {
+		return x.Taxid
+	}
+	return 0
+}
+
+func (x *TaxIDName) GetScientificName() string {
+	if x != nil {
+		return x.ScientificName
+	}
+	return ""
+}
+
+type Name2TaxIDItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Taxids        []*TaxIDName           `protobuf:"bytes,2,rep,name=taxids,proto3" json:"taxids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Name2TaxIDItem) Reset() {
+	*x = Name2TaxIDItem{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Name2TaxIDItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Name2TaxIDItem) ProtoMessage() {}
+
+func (x *Name2TaxIDItem) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Name2TaxIDItem.ProtoReflect.Descriptor instead.
+func (*Name2TaxIDItem) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Name2TaxIDItem) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *Name2TaxIDItem) GetTaxids() []*TaxIDName {
+	if x != nil {
+		return x.Taxids
+	}
+	return nil
+}
+
+type Name2TaxIDResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Name2TaxIDItem      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Name2TaxIDResponse) Reset() {
+	*x = Name2TaxIDResponse{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Name2TaxIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Name2TaxIDResponse) ProtoMessage() {}
+
+func (x *Name2TaxIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Name2TaxIDResponse.ProtoReflect.Descriptor instead.
+func (*Name2TaxIDResponse) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Name2TaxIDResponse) GetItems() []*Name2TaxIDItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type TaxIDGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Taxids        []int64                `protobuf:"varint,1,rep,packed,name=taxids,proto3" json:"taxids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaxIDGroup) Reset() {
+	*x = TaxIDGroup{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaxIDGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaxIDGroup) ProtoMessage() {}
+
+func (x *TaxIDGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaxIDGroup.ProtoReflect.Descriptor instead.
+func (*TaxIDGroup) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TaxIDGroup) GetTaxids() []int64 {
+	if x != nil {
+		return x.Taxids
+	}
+	return nil
+}
+
+type LCARequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*TaxIDGroup          `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LCARequest) Reset() {
+	*x = LCARequest{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LCARequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LCARequest) ProtoMessage() {}
+
+func (x *LCARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LCARequest.ProtoReflect.Descriptor instead.
+func (*LCARequest) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LCARequest) GetGroups() []*TaxIDGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type LCAItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Taxids        []int64                `protobuf:"varint,1,rep,packed,name=taxids,proto3" json:"taxids,omitempty"`
+	Lca           *Taxon                 `protobuf:"bytes,2,opt,name=lca,proto3" json:"lca,omitempty"` // unset if not found
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LCAItem) Reset() {
+	*x = LCAItem{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LCAItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LCAItem) ProtoMessage() {}
+
+func (x *LCAItem) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LCAItem.ProtoReflect.Descriptor instead.
+func (*LCAItem) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LCAItem) GetTaxids() []int64 {
+	if x != nil {
+		return x.Taxids
+	}
+	return nil
+}
+
+func (x *LCAItem) GetLca() *Taxon {
+	if x != nil {
+		return x.Lca
+	}
+	return nil
+}
+
+type LCAResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*LCAItem             `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LCAResponse) Reset() {
+	*x = LCAResponse{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LCAResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LCAResponse) ProtoMessage() {}
+
+func (x *LCAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LCAResponse.ProtoReflect.Descriptor instead.
+func (*LCAResponse) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LCAResponse) GetItems() []*LCAItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type LineageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Taxids        []int64                `protobuf:"varint,1,rep,packed,name=taxids,proto3" json:"taxids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LineageRequest) Reset() {
+	*x = LineageRequest{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LineageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineageRequest) ProtoMessage() {}
+
+func (x *LineageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineageRequest.ProtoReflect.Descriptor instead.
+func (*LineageRequest) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LineageRequest) GetTaxids() []int64 {
+	if x != nil {
+		return x.Taxids
+	}
+	return nil
+}
+
+type LineageItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Taxid         int64                  `protobuf:"varint,1,opt,name=taxid,proto3" json:"taxid,omitempty"`
+	Lineage       []*LineageNode         `protobuf:"bytes,2,rep,name=lineage,proto3" json:"lineage,omitempty"` // empty if not found
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LineageItem) Reset() {
+	*x = LineageItem{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LineageItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineageItem) ProtoMessage() {}
+
+func (x *LineageItem) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineageItem.ProtoReflect.Descriptor instead.
+func (*LineageItem) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LineageItem) GetTaxid() int64 {
+	if x != nil {
+		return x.Taxid
+	}
+	return 0
+}
+
+func (x *LineageItem) GetLineage() []*LineageNode {
+	if x != nil {
+		return x.Lineage
+	}
+	return nil
+}
+
+type LineageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*LineageItem         `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LineageResponse) Reset() {
+	*x = LineageResponse{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LineageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineageResponse) ProtoMessage() {}
+
+func (x *LineageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineageResponse.ProtoReflect.Descriptor instead.
+func (*LineageResponse) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *LineageResponse) GetItems() []*LineageItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type LineageNode struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Taxid          int64                  `protobuf:"varint,1,opt,name=taxid,proto3" json:"taxid,omitempty"`
+	ScientificName string                 `protobuf:"bytes,2,opt,name=scientific_name,json=scientificName,proto3" json:"scientific_name,omitempty"`
+	Rank           string                 `protobuf:"bytes,3,opt,name=rank,proto3" json:"rank,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LineageNode) Reset() {
+	*x = LineageNode{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LineageNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LineageNode) ProtoMessage() {}
+
+func (x *LineageNode) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LineageNode.ProtoReflect.Descriptor instead.
+func (*LineageNode) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *LineageNode) GetTaxid() int64 {
+	if x != nil {
+		return x.Taxid
+	}
+	return 0
+}
+
+func (x *LineageNode) GetScientificName() string {
+	if x != nil {
+		return x.ScientificName
+	}
+	return ""
+}
+
+func (x *LineageNode) GetRank() string {
+	if x != nil {
+		return x.Rank
+	}
+	return ""
+}
+
+type OtherName struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NameClass     string                 `protobuf:"bytes,1,opt,name=name_class,json=nameClass,proto3" json:"name_class,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OtherName) Reset() {
+	*x = OtherName{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OtherName) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OtherName) ProtoMessage() {}
+
+func (x *OtherName) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OtherName.ProtoReflect.Descriptor instead.
+func (*OtherName) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *OtherName) GetNameClass() string {
+	if x != nil {
+		return x.NameClass
+	}
+	return ""
+}
+
+func (x *OtherName) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GeneticCode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GeneticCode) Reset() {
+	*x = GeneticCode{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeneticCode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeneticCode) ProtoMessage() {}
+
+func (x *GeneticCode) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeneticCode.ProtoReflect.Descriptor instead.
+func (*GeneticCode) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GeneticCode) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GeneticCode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Taxon struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Taxid           int64                  `protobuf:"varint,1,opt,name=taxid,proto3" json:"taxid,omitempty"`
+	ScientificName  string                 `protobuf:"bytes,2,opt,name=scientific_name,json=scientificName,proto3" json:"scientific_name,omitempty"`
+	OtherNames      []*OtherName           `protobuf:"bytes,3,rep,name=other_names,json=otherNames,proto3" json:"other_names,omitempty"`
+	ParentTaxid     int64                  `protobuf:"varint,4,opt,name=parent_taxid,json=parentTaxid,proto3" json:"parent_taxid,omitempty"`
+	Rank            string                 `protobuf:"bytes,5,opt,name=rank,proto3" json:"rank,omitempty"`
+	Division        string                 `protobuf:"bytes,6,opt,name=division,proto3" json:"division,omitempty"`
+	GeneticCode     *GeneticCode           `protobuf:"bytes,7,opt,name=genetic_code,json=geneticCode,proto3" json:"genetic_code,omitempty"`
+	MitoGeneticCode *GeneticCode           `protobuf:"bytes,8,opt,name=mito_genetic_code,json=mitoGeneticCode,proto3" json:"mito_genetic_code,omitempty"`
+	Lineage         string                 `protobuf:"bytes,9,opt,name=lineage,proto3" json:"lineage,omitempty"`
+	LineageEx       []*LineageNode         `protobuf:"bytes,10,rep,name=lineage_ex,json=lineageEx,proto3" json:"lineage_ex,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Taxon) Reset() {
+	*x = Taxon{}
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Taxon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Taxon) ProtoMessage() {}
+
+func (x *Taxon) ProtoReflect() protoreflect.Message {
+	mi := &file_taxon_pb_gtaxon_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Taxon.ProtoReflect.Descriptor instead.
+func (*Taxon) Descriptor() ([]byte, []int) {
+	return file_taxon_pb_gtaxon_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Taxon) GetTaxid() int64 {
+	if x != nil {
+		return x.Taxid
+	}
+	return 0
+}
+
+func (x *Taxon) GetScientificName() string {
+	if x != nil {
+		return x.ScientificName
+	}
+	return ""
+}
+
+func (x *Taxon) GetOtherNames() []*OtherName {
+	if x != nil {
+		return x.OtherNames
+	}
+	return nil
+}
+
+func (x *Taxon) GetParentTaxid() int64 {
+	if x != nil {
+		return x.ParentTaxid
+	}
+	return 0
+}
+
+func (x *Taxon) GetRank() string {
+	if x != nil {
+		return x.Rank
+	}
+	return ""
+}
+
+func (x *Taxon) GetDivision() string {
+	if x != nil {
+		return x.Division
+	}
+	return ""
+}
+
+func (x *Taxon) GetGeneticCode() *GeneticCode {
+	if x != nil {
+		return x.GeneticCode
+	}
+	return nil
+}
+
+func (x *Taxon) GetMitoGeneticCode() *GeneticCode {
+	if x != nil {
+		return x.MitoGeneticCode
+	}
+	return nil
+}
+
+func (x *Taxon) GetLineage() string {
+	if x != nil {
+		return x.Lineage
+	}
+	return ""
+}
+
+func (x *Taxon) GetLineageEx() []*LineageNode {
+	if x != nil {
+		return x.LineageEx
+	}
+	return nil
+}
+
+var File_taxon_pb_gtaxon_proto protoreflect.FileDescriptor
+
+const file_taxon_pb_gtaxon_proto_rawDesc = "" +
+	"\n" +
+	"\x15taxon/pb/gtaxon.proto\x12\x06gtaxon\"Q\n" +
+	"\x0fGi2TaxIDRequest\x12,\n" +
+	"\bmolecule\x18\x01 \x01(\x0e2\x10.gtaxon.MoleculeR\bmolecule\x12\x10\n" +
+	"\x03gis\x18\x02 \x03(\tR\x03gis\"`\n" +
+	"\x10Acc2TaxIDRequest\x12,\n" +
+	"\bmolecule\x18\x01 \x01(\x0e2\x10.gtaxon.MoleculeR\bmolecule\x12\x1e\n" +
+	"\n" +
+	"accessions\x18\x02 \x03(\tR\n" +
+	"accessions\"7\n" +
+	"\tTaxIDItem\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05taxid\x18\x02 \x01(\x03R\x05taxid\"8\n" +
+	"\rTaxIDResponse\x12'\n" +
+	"\x05items\x18\x01 \x03(\v2\x11.gtaxon.TaxIDItemR\x05items\",\n" +
+	"\x12TaxID2TaxonRequest\x12\x16\n" +
+	"\x06taxids\x18\x01 \x03(\x03R\x06taxids\"L\n" +
+	"\x0fTaxID2TaxonItem\x12\x14\n" +
+	"\x05taxid\x18\x01 \x01(\x03R\x05taxid\x12#\n" +
+	"\x05taxon\x18\x02 \x01(\v2\r.gtaxon.TaxonR\x05taxon\"D\n" +
+	"\x13TaxID2TaxonResponse\x12-\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.gtaxon.TaxID2TaxonItemR\x05items\"`\n" +
+	"\x11Name2TaxIDRequest\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\x12\x16\n" +
+	"\x06regexp\x18\x02 \x01(\bR\x06regexp\x12\x1d\n" +
+	"\n" +
+	"name_class\x18\x03 \x01(\tR\tnameClass\"J\n" +
+	"\tTaxIDName\x12\x14\n" +
+	"\x05taxid\x18\x01 \x01(\x03R\x05taxid\x12'\n" +
+	"\x0fscientific_name\x18\x02 \x01(\tR\x0escientificName\"Q\n" +
+	"\x0eName2TaxIDItem\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12)\n" +
+	"\x06taxids\x18\x02 \x03(\v2\x11.gtaxon.TaxIDNameR\x06taxids\"B\n" +
+	"\x12Name2TaxIDResponse\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.gtaxon.Name2TaxIDItemR\x05items\"$\n" +
+	"\n" +
+	"TaxIDGroup\x12\x16\n" +
+	"\x06taxids\x18\x01 \x03(\x03R\x06taxids\"8\n" +
+	"\n" +
+	"LCARequest\x12*\n" +
+	"\x06groups\x18\x01 \x03(\v2\x12.gtaxon.TaxIDGroupR\x06groups\"B\n" +
+	"\aLCAItem\x12\x16\n" +
+	"\x06taxids\x18\x01 \x03(\x03R\x06taxids\x12\x1f\n" +
+	"\x03lca\x18\x02 \x01(\v2\r.gtaxon.TaxonR\x03lca\"4\n" +
+	"\vLCAResponse\x12%\n" +
+	"\x05items\x18\x01 \x03(\v2\x0f.gtaxon.LCAItemR\x05items\"(\n" +
+	"\x0eLineageRequest\x12\x16\n" +
+	"\x06taxids\x18\x01 \x03(\x03R\x06taxids\"R\n" +
+	"\vLineageItem\x12\x14\n" +
+	"\x05taxid\x18\x01 \x01(\x03R\x05taxid\x12-\n" +
+	"\alineage\x18\x02 \x03(\v2\x13.gtaxon.LineageNodeR\alineage\"<\n" +
+	"\x0fLineageResponse\x12)\n" +
+	"\x05items\x18\x01 \x03(\v2\x13.gtaxon.LineageItemR\x05items\"`\n" +
+	"\vLineageNode\x12\x14\n" +
+	"\x05taxid\x18\x01 \x01(\x03R\x05taxid\x12'\n" +
+	"\x0fscientific_name\x18\x02 \x01(\tR\x0escientificName\x12\x12\n" +
+	"\x04rank\x18\x03 \x01(\tR\x04rank\">\n" +
+	"\tOtherName\x12\x1d\n" +
+	"\n" +
+	"name_class\x18\x01 \x01(\tR\tnameClass\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"1\n" +
+	"\vGeneticCode\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x94\x03\n" +
+	"\x05Taxon\x12\x14\n" +
+	"\x05taxid\x18\x01 \x01(\x03R\x05taxid\x12'\n" +
+	"\x0fscientific_name\x18\x02 \x01(\tR\x0escientificName\x122\n" +
+	"\vother_names\x18\x03 \x03(\v2\x11.gtaxon.OtherNameR\n" +
+	"otherNames\x12!\n" +
+	"\fparent_taxid\x18\x04 \x01(\x03R\vparentTaxid\x12\x12\n" +
+	"\x04rank\x18\x05 \x01(\tR\x04rank\x12\x1a\n" +
+	"\bdivision\x18\x06 \x01(\tR\bdivision\x126\n" +
+	"\fgenetic_code\x18\a \x01(\v2\x13.gtaxon.GeneticCodeR\vgeneticCode\x12?\n" +
+	"\x11mito_genetic_code\x18\b \x01(\v2\x13.gtaxon.GeneticCodeR\x0fmitoGeneticCode\x12\x18\n" +
+	"\alineage\x18\t \x01(\tR\alineage\x122\n" +
+	"\n" +
+	"lineage_ex\x18\n" +
+	" \x03(\v2\x13.gtaxon.LineageNodeR\tlineageEx*\x1e\n" +
+	"\bMolecule\x12\b\n" +
+	"\x04PROT\x10\x00\x12\b\n" +
+	"\x04NUCL\x10\x012\x86\x06\n" +
+	"\x06GTaxon\x12:\n" +
+	"\bGi2TaxID\x12\x17.gtaxon.Gi2TaxIDRequest\x1a\x15.gtaxon.TaxIDResponse\x12>\n" +
+	"\x0eGi2TaxIDStream\x12\x17.gtaxon.Gi2TaxIDRequest\x1a\x11.gtaxon.TaxIDItem0\x01\x12<\n" +
+	"\tAcc2TaxID\x12\x18.gtaxon.Acc2TaxIDRequest\x1a\x15.gtaxon.TaxIDResponse\x12@\n" +
+	"\x0fAcc2TaxIDStream\x12\x18.gtaxon.Acc2TaxIDRequest\x1a\x11.gtaxon.TaxIDItem0\x01\x12F\n" +
+	"\vTaxID2Taxon\x12\x1a.gtaxon.TaxID2TaxonRequest\x1a\x1b.gtaxon.TaxID2TaxonResponse\x12J\n" +
+	"\x11TaxID2TaxonStream\x12\x1a.gtaxon.TaxID2TaxonRequest\x1a\x17.gtaxon.TaxID2TaxonItem0\x01\x12C\n" +
+	"\n" +
+	"Name2TaxID\x12\x19.gtaxon.Name2TaxIDRequest\x1a\x1a.gtaxon.Name2TaxIDResponse\x12G\n" +
+	"\x10Name2TaxIDStream\x12\x19.gtaxon.Name2TaxIDRequest\x1a\x16.gtaxon.Name2TaxIDItem0\x01\x12.\n" +
+	"\x03LCA\x12\x12.gtaxon.LCARequest\x1a\x13.gtaxon.LCAResponse\x122\n" +
+	"\tLCAStream\x12\x12.gtaxon.LCARequest\x1a\x0f.gtaxon.LCAItem0\x01\x12:\n" +
+	"\aLineage\x12\x16.gtaxon.LineageRequest\x1a\x17.gtaxon.LineageResponse\x12>\n" +
+	"\rLineageStream\x12\x16.gtaxon.LineageRequest\x1a\x13.gtaxon.LineageItem0\x01B'Z%github.com/shenwei356/gtaxon/taxon/pbb\x06proto3"
+
+var (
+	file_taxon_pb_gtaxon_proto_rawDescOnce sync.Once
+	file_taxon_pb_gtaxon_proto_rawDescData []byte
+)
+
+func file_taxon_pb_gtaxon_proto_rawDescGZIP() []byte {
+	file_taxon_pb_gtaxon_proto_rawDescOnce.Do(func() {
+		file_taxon_pb_gtaxon_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_taxon_pb_gtaxon_proto_rawDesc), len(file_taxon_pb_gtaxon_proto_rawDesc)))
+	})
+	return file_taxon_pb_gtaxon_proto_rawDescData
+}
+
+var file_taxon_pb_gtaxon_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_taxon_pb_gtaxon_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_taxon_pb_gtaxon_proto_goTypes = []any{
+	(Molecule)(0),               // 0: gtaxon.Molecule
+	(*Gi2TaxIDRequest)(nil),     // 1: gtaxon.Gi2TaxIDRequest
+	(*Acc2TaxIDRequest)(nil),    // 2: gtaxon.Acc2TaxIDRequest
+	(*TaxIDItem)(nil),           // 3: gtaxon.TaxIDItem
+	(*TaxIDResponse)(nil),       // 4: gtaxon.TaxIDResponse
+	(*TaxID2TaxonRequest)(nil),  // 5: gtaxon.TaxID2TaxonRequest
+	(*TaxID2TaxonItem)(nil),     // 6: gtaxon.TaxID2TaxonItem
+	(*TaxID2TaxonResponse)(nil), // 7: gtaxon.TaxID2TaxonResponse
+	(*Name2TaxIDRequest)(nil),   // 8: gtaxon.Name2TaxIDRequest
+	(*TaxIDName)(nil),           // 9: gtaxon.TaxIDName
+	(*Name2TaxIDItem)(nil),      // 10: gtaxon.Name2TaxIDItem
+	(*Name2TaxIDResponse)(nil),  // 11: gtaxon.Name2TaxIDResponse
+	(*TaxIDGroup)(nil),          // 12: gtaxon.TaxIDGroup
+	(*LCARequest)(nil),          // 13: gtaxon.LCARequest
+	(*LCAItem)(nil),             // 14: gtaxon.LCAItem
+	(*LCAResponse)(nil),         // 15: gtaxon.LCAResponse
+	(*LineageRequest)(nil),      // 16: gtaxon.LineageRequest
+	(*LineageItem)(nil),         // 17: gtaxon.LineageItem
+	(*LineageResponse)(nil),     // 18: gtaxon.LineageResponse
+	(*LineageNode)(nil),         // 19: gtaxon.LineageNode
+	(*OtherName)(nil),           // 20: gtaxon.OtherName
+	(*GeneticCode)(nil),         // 21: gtaxon.GeneticCode
+	(*Taxon)(nil),               // 22: gtaxon.Taxon
+}
+var file_taxon_pb_gtaxon_proto_depIdxs = []int32{
+	0,  // 0: gtaxon.Gi2TaxIDRequest.molecule:type_name -> gtaxon.Molecule
+	0,  // 1: gtaxon.Acc2TaxIDRequest.molecule:type_name -> gtaxon.Molecule
+	3,  // 2: gtaxon.TaxIDResponse.items:type_name -> gtaxon.TaxIDItem
+	22, // 3: gtaxon.TaxID2TaxonItem.taxon:type_name -> gtaxon.Taxon
+	6,  // 4: gtaxon.TaxID2TaxonResponse.items:type_name -> gtaxon.TaxID2TaxonItem
+	9,  // 5: gtaxon.Name2TaxIDItem.taxids:type_name -> gtaxon.TaxIDName
+	10, // 6: gtaxon.Name2TaxIDResponse.items:type_name -> gtaxon.Name2TaxIDItem
+	12, // 7: gtaxon.LCARequest.groups:type_name -> gtaxon.TaxIDGroup
+	22, // 8: gtaxon.LCAItem.lca:type_name -> gtaxon.Taxon
+	14, // 9: gtaxon.LCAResponse.items:type_name -> gtaxon.LCAItem
+	19, // 10: gtaxon.LineageItem.lineage:type_name -> gtaxon.LineageNode
+	17, // 11: gtaxon.LineageResponse.items:type_name -> gtaxon.LineageItem
+	20, // 12: gtaxon.Taxon.other_names:type_name -> gtaxon.OtherName
+	21, // 13: gtaxon.Taxon.genetic_code:type_name -> gtaxon.GeneticCode
+	21, // 14: gtaxon.Taxon.mito_genetic_code:type_name -> gtaxon.GeneticCode
+	19, // 15: gtaxon.Taxon.lineage_ex:type_name -> gtaxon.LineageNode
+	1,  // 16: gtaxon.GTaxon.Gi2TaxID:input_type -> gtaxon.Gi2TaxIDRequest
+	1,  // 17: gtaxon.GTaxon.Gi2TaxIDStream:input_type -> gtaxon.Gi2TaxIDRequest
+	2,  // 18: gtaxon.GTaxon.Acc2TaxID:input_type -> gtaxon.Acc2TaxIDRequest
+	2,  // 19: gtaxon.GTaxon.Acc2TaxIDStream:input_type -> gtaxon.Acc2TaxIDRequest
+	5,  // 20: gtaxon.GTaxon.TaxID2Taxon:input_type -> gtaxon.TaxID2TaxonRequest
+	5,  // 21: gtaxon.GTaxon.TaxID2TaxonStream:input_type -> gtaxon.TaxID2TaxonRequest
+	8,  // 22: gtaxon.GTaxon.Name2TaxID:input_type -> gtaxon.Name2TaxIDRequest
+	8,  // 23: gtaxon.GTaxon.Name2TaxIDStream:input_type -> gtaxon.Name2TaxIDRequest
+	13, // 24: gtaxon.GTaxon.LCA:input_type -> gtaxon.LCARequest
+	13, // 25: gtaxon.GTaxon.LCAStream:input_type -> gtaxon.LCARequest
+	16, // 26: gtaxon.GTaxon.Lineage:input_type -> gtaxon.LineageRequest
+	16, // 27: gtaxon.GTaxon.LineageStream:input_type -> gtaxon.LineageRequest
+	4,  // 28: gtaxon.GTaxon.Gi2TaxID:output_type -> gtaxon.TaxIDResponse
+	3,  // 29: gtaxon.GTaxon.Gi2TaxIDStream:output_type -> gtaxon.TaxIDItem
+	4,  // 30: gtaxon.GTaxon.Acc2TaxID:output_type -> gtaxon.TaxIDResponse
+	3,  // 31: gtaxon.GTaxon.Acc2TaxIDStream:output_type -> gtaxon.TaxIDItem
+	7,  // 32: gtaxon.GTaxon.TaxID2Taxon:output_type -> gtaxon.TaxID2TaxonResponse
+	6,  // 33: gtaxon.GTaxon.TaxID2TaxonStream:output_type -> gtaxon.TaxID2TaxonItem
+	11, // 34: gtaxon.GTaxon.Name2TaxID:output_type -> gtaxon.Name2TaxIDResponse
+	10, // 35: gtaxon.GTaxon.Name2TaxIDStream:output_type -> gtaxon.Name2TaxIDItem
+	15, // 36: gtaxon.GTaxon.LCA:output_type -> gtaxon.LCAResponse
+	14, // 37: gtaxon.GTaxon.LCAStream:output_type -> gtaxon.LCAItem
+	18, // 38: gtaxon.GTaxon.Lineage:output_type -> gtaxon.LineageResponse
+	17, // 39: gtaxon.GTaxon.LineageStream:output_type -> gtaxon.LineageItem
+	28, // [28:40] is the sub-list for method output_type
+	16, // [16:28] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_taxon_pb_gtaxon_proto_init() }
+func file_taxon_pb_gtaxon_proto_init() {
+	if File_taxon_pb_gtaxon_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_taxon_pb_gtaxon_proto_rawDesc), len(file_taxon_pb_gtaxon_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_taxon_pb_gtaxon_proto_goTypes,
+		DependencyIndexes: file_taxon_pb_gtaxon_proto_depIdxs,
+		EnumInfos:         file_taxon_pb_gtaxon_proto_enumTypes,
+		MessageInfos:      file_taxon_pb_gtaxon_proto_msgTypes,
+	}.Build()
+	File_taxon_pb_gtaxon_proto = out.File
+	file_taxon_pb_gtaxon_proto_goTypes = nil
+	file_taxon_pb_gtaxon_proto_depIdxs = nil
+}
@@ -0,0 +1,584 @@
+// gRPC service of gtaxon server.
+//
+// Go code is generated by:
+//
+//     protoc --go_out=. --go_opt=paths=source_relative \
+//         --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//         taxon/pb/gtaxon.proto
+//
+// Results are in the same order of queries. Methods ending with "Stream"
+// send results in chunks as soon as they are resolved, suitable for large
+// batches. API key, if required by server, is sent in metadata "x-api-key".
+// Failures are reported with gRPC status carrying google.rpc.ErrorInfo,
+// whose reason is the stable error code of REST API, e.g., "invalid_query".
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: taxon/pb/gtaxon.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GTaxon_Gi2TaxID_FullMethodName          = "/gtaxon.GTaxon/Gi2TaxID"
+	GTaxon_Gi2TaxIDStream_FullMethodName    = "/gtaxon.GTaxon/Gi2TaxIDStream"
+	GTaxon_Acc2TaxID_FullMethodName         = "/gtaxon.GTaxon/Acc2TaxID"
+	GTaxon_Acc2TaxIDStream_FullMethodName   = "/gtaxon.GTaxon/Acc2TaxIDStream"
+	GTaxon_TaxID2Taxon_FullMethodName       = "/gtaxon.GTaxon/TaxID2Taxon"
+	GTaxon_TaxID2TaxonStream_FullMethodName = "/gtaxon.GTaxon/TaxID2TaxonStream"
+	GTaxon_Name2TaxID_FullMethodName        = "/gtaxon.GTaxon/Name2TaxID"
+	GTaxon_Name2TaxIDStream_FullMethodName  = "/gtaxon.GTaxon/Name2TaxIDStream"
+	GTaxon_LCA_FullMethodName               = "/gtaxon.GTaxon/LCA"
+	GTaxon_LCAStream_FullMethodName         = "/gtaxon.GTaxon/LCAStream"
+	GTaxon_Lineage_FullMethodName           = "/gtaxon.GTaxon/Lineage"
+	GTaxon_LineageStream_FullMethodName     = "/gtaxon.GTaxon/LineageStream"
+)
+
+// GTaxonClient is the client API for GTaxon service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GTaxonClient interface {
+	// Gi2TaxID querys taxids by GIs
+	Gi2TaxID(ctx context.Context, in *Gi2TaxIDRequest, opts ...grpc.CallOption) (*TaxIDResponse, error)
+	Gi2TaxIDStream(ctx context.Context, in *Gi2TaxIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaxIDItem], error)
+	// Acc2TaxID querys taxids by accessions, with or without version
+	Acc2TaxID(ctx context.Context, in *Acc2TaxIDRequest, opts ...grpc.CallOption) (*TaxIDResponse, error)
+	Acc2TaxIDStream(ctx context.Context, in *Acc2TaxIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaxIDItem], error)
+	// TaxID2Taxon querys Taxons by taxids
+	TaxID2Taxon(ctx context.Context, in *TaxID2TaxonRequest, opts ...grpc.CallOption) (*TaxID2TaxonResponse, error)
+	TaxID2TaxonStream(ctx context.Context, in *TaxID2TaxonRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaxID2TaxonItem], error)
+	// Name2TaxID querys taxids and scientific names by names
+	Name2TaxID(ctx context.Context, in *Name2TaxIDRequest, opts ...grpc.CallOption) (*Name2TaxIDResponse, error)
+	Name2TaxIDStream(ctx context.Context, in *Name2TaxIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Name2TaxIDItem], error)
+	// LCA querys Lowest Common Ancestors of groups of taxids
+	LCA(ctx context.Context, in *LCARequest, opts ...grpc.CallOption) (*LCAResponse, error)
+	LCAStream(ctx context.Context, in *LCARequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LCAItem], error)
+	// Lineage querys lineages of taxids, from the child of root to the taxid itself
+	Lineage(ctx context.Context, in *LineageRequest, opts ...grpc.CallOption) (*LineageResponse, error)
+	LineageStream(ctx context.Context, in *LineageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LineageItem], error)
+}
+
+type gTaxonClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGTaxonClient(cc grpc.ClientConnInterface) GTaxonClient {
+	return &gTaxonClient{cc}
+}
+
+func (c *gTaxonClient) Gi2TaxID(ctx context.Context, in *Gi2TaxIDRequest, opts ...grpc.CallOption) (*TaxIDResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaxIDResponse)
+	err := c.cc.Invoke(ctx, GTaxon_Gi2TaxID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gTaxonClient) Gi2TaxIDStream(ctx context.Context, in *Gi2TaxIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaxIDItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GTaxon_ServiceDesc.Streams[0], GTaxon_Gi2TaxIDStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Gi2TaxIDRequest, TaxIDItem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_Gi2TaxIDStreamClient = grpc.ServerStreamingClient[TaxIDItem]
+
+func (c *gTaxonClient) Acc2TaxID(ctx context.Context, in *Acc2TaxIDRequest, opts ...grpc.CallOption) (*TaxIDResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaxIDResponse)
+	err := c.cc.Invoke(ctx, GTaxon_Acc2TaxID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gTaxonClient) Acc2TaxIDStream(ctx context.Context, in *Acc2TaxIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaxIDItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GTaxon_ServiceDesc.Streams[1], GTaxon_Acc2TaxIDStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Acc2TaxIDRequest, TaxIDItem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_Acc2TaxIDStreamClient = grpc.ServerStreamingClient[TaxIDItem]
+
+func (c *gTaxonClient) TaxID2Taxon(ctx context.Context, in *TaxID2TaxonRequest, opts ...grpc.CallOption) (*TaxID2TaxonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaxID2TaxonResponse)
+	err := c.cc.Invoke(ctx, GTaxon_TaxID2Taxon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gTaxonClient) TaxID2TaxonStream(ctx context.Context, in *TaxID2TaxonRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaxID2TaxonItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GTaxon_ServiceDesc.Streams[2], GTaxon_TaxID2TaxonStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TaxID2TaxonRequest, TaxID2TaxonItem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_TaxID2TaxonStreamClient = grpc.ServerStreamingClient[TaxID2TaxonItem]
+
+func (c *gTaxonClient) Name2TaxID(ctx context.Context, in *Name2TaxIDRequest, opts ...grpc.CallOption) (*Name2TaxIDResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Name2TaxIDResponse)
+	err := c.cc.Invoke(ctx, GTaxon_Name2TaxID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gTaxonClient) Name2TaxIDStream(ctx context.Context, in *Name2TaxIDRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Name2TaxIDItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GTaxon_ServiceDesc.Streams[3], GTaxon_Name2TaxIDStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Name2TaxIDRequest, Name2TaxIDItem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_Name2TaxIDStreamClient = grpc.ServerStreamingClient[Name2TaxIDItem]
+
+func (c *gTaxonClient) LCA(ctx context.Context, in *LCARequest, opts ...grpc.CallOption) (*LCAResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LCAResponse)
+	err := c.cc.Invoke(ctx, GTaxon_LCA_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gTaxonClient) LCAStream(ctx context.Context, in *LCARequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LCAItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GTaxon_ServiceDesc.Streams[4], GTaxon_LCAStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LCARequest, LCAItem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_LCAStreamClient = grpc.ServerStreamingClient[LCAItem]
+
+func (c *gTaxonClient) Lineage(ctx context.Context, in *LineageRequest, opts ...grpc.CallOption) (*LineageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LineageResponse)
+	err := c.cc.Invoke(ctx, GTaxon_Lineage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gTaxonClient) LineageStream(ctx context.Context, in *LineageRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LineageItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GTaxon_ServiceDesc.Streams[5], GTaxon_LineageStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LineageRequest, LineageItem]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_LineageStreamClient = grpc.ServerStreamingClient[LineageItem]
+
+// GTaxonServer is the server API for GTaxon service.
+// All implementations must embed UnimplementedGTaxonServer
+// for forward compatibility.
+type GTaxonServer interface {
+	// Gi2TaxID querys taxids by GIs
+	Gi2TaxID(context.Context, *Gi2TaxIDRequest) (*TaxIDResponse, error)
+	Gi2TaxIDStream(*Gi2TaxIDRequest, grpc.ServerStreamingServer[TaxIDItem]) error
+	// Acc2TaxID querys taxids by accessions, with or without version
+	Acc2TaxID(context.Context, *Acc2TaxIDRequest) (*TaxIDResponse, error)
+	Acc2TaxIDStream(*Acc2TaxIDRequest, grpc.ServerStreamingServer[TaxIDItem]) error
+	// TaxID2Taxon querys Taxons by taxids
+	TaxID2Taxon(context.Context, *TaxID2TaxonRequest) (*TaxID2TaxonResponse, error)
+	TaxID2TaxonStream(*TaxID2TaxonRequest, grpc.ServerStreamingServer[TaxID2TaxonItem]) error
+	// Name2TaxID querys taxids and scientific names by names
+	Name2TaxID(context.Context, *Name2TaxIDRequest) (*Name2TaxIDResponse, error)
+	Name2TaxIDStream(*Name2TaxIDRequest, grpc.ServerStreamingServer[Name2TaxIDItem]) error
+	// LCA querys Lowest Common Ancestors of groups of taxids
+	LCA(context.Context, *LCARequest) (*LCAResponse, error)
+	LCAStream(*LCARequest, grpc.ServerStreamingServer[LCAItem]) error
+	// Lineage querys lineages of taxids, from the child of root to the taxid itself
+	Lineage(context.Context, *LineageRequest) (*LineageResponse, error)
+	LineageStream(*LineageRequest, grpc.ServerStreamingServer[LineageItem]) error
+	mustEmbedUnimplementedGTaxonServer()
+}
+
+// UnimplementedGTaxonServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGTaxonServer struct{}
+
+func (UnimplementedGTaxonServer) Gi2TaxID(context.Context, *Gi2TaxIDRequest) (*TaxIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Gi2TaxID not implemented")
+}
+func (UnimplementedGTaxonServer) Gi2TaxIDStream(*Gi2TaxIDRequest, grpc.ServerStreamingServer[TaxIDItem]) error {
+	return status.Errorf(codes.Unimplemented, "method Gi2TaxIDStream not implemented")
+}
+func (UnimplementedGTaxonServer) Acc2TaxID(context.Context, *Acc2TaxIDRequest) (*TaxIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Acc2TaxID not implemented")
+}
+func (UnimplementedGTaxonServer) Acc2TaxIDStream(*Acc2TaxIDRequest, grpc.ServerStreamingServer[TaxIDItem]) error {
+	return status.Errorf(codes.Unimplemented, "method Acc2TaxIDStream not implemented")
+}
+func (UnimplementedGTaxonServer) TaxID2Taxon(context.Context, *TaxID2TaxonRequest) (*TaxID2TaxonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TaxID2Taxon not implemented")
+}
+func (UnimplementedGTaxonServer) TaxID2TaxonStream(*TaxID2TaxonRequest, grpc.ServerStreamingServer[TaxID2TaxonItem]) error {
+	return status.Errorf(codes.Unimplemented, "method TaxID2TaxonStream not implemented")
+}
+func (UnimplementedGTaxonServer) Name2TaxID(context.Context, *Name2TaxIDRequest) (*Name2TaxIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Name2TaxID not implemented")
+}
+func (UnimplementedGTaxonServer) Name2TaxIDStream(*Name2TaxIDRequest, grpc.ServerStreamingServer[Name2TaxIDItem]) error {
+	return status.Errorf(codes.Unimplemented, "method Name2TaxIDStream not implemented")
+}
+func (UnimplementedGTaxonServer) LCA(context.Context, *LCARequest) (*LCAResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LCA not implemented")
+}
+func (UnimplementedGTaxonServer) LCAStream(*LCARequest, grpc.ServerStreamingServer[LCAItem]) error {
+	return status.Errorf(codes.Unimplemented, "method LCAStream not implemented")
+}
+func (UnimplementedGTaxonServer) Lineage(context.Context, *LineageRequest) (*LineageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lineage not implemented")
+}
+func (UnimplementedGTaxonServer) LineageStream(*LineageRequest, grpc.ServerStreamingServer[LineageItem]) error {
+	return status.Errorf(codes.Unimplemented, "method LineageStream not implemented")
+}
+func (UnimplementedGTaxonServer) mustEmbedUnimplementedGTaxonServer() {}
+func (UnimplementedGTaxonServer) testEmbeddedByValue()                {}
+
+// UnsafeGTaxonServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GTaxonServer will
+// result in compilation errors.
+type UnsafeGTaxonServer interface {
+	mustEmbedUnimplementedGTaxonServer()
+}
+
+func RegisterGTaxonServer(s grpc.ServiceRegistrar, srv GTaxonServer) {
+	// If the following call pancis, it indicates UnimplementedGTaxonServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GTaxon_ServiceDesc, srv)
+}
+
+func _GTaxon_Gi2TaxID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Gi2TaxIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GTaxonServer).Gi2TaxID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GTaxon_Gi2TaxID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GTaxonServer).Gi2TaxID(ctx, req.(*Gi2TaxIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GTaxon_Gi2TaxIDStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Gi2TaxIDRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GTaxonServer).Gi2TaxIDStream(m, &grpc.GenericServerStream[Gi2TaxIDRequest, TaxIDItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_Gi2TaxIDStreamServer = grpc.ServerStreamingServer[TaxIDItem]
+
+func _GTaxon_Acc2TaxID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Acc2TaxIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GTaxonServer).Acc2TaxID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GTaxon_Acc2TaxID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GTaxonServer).Acc2TaxID(ctx, req.(*Acc2TaxIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GTaxon_Acc2TaxIDStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Acc2TaxIDRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GTaxonServer).Acc2TaxIDStream(m, &grpc.GenericServerStream[Acc2TaxIDRequest, TaxIDItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_Acc2TaxIDStreamServer = grpc.ServerStreamingServer[TaxIDItem]
+
+func _GTaxon_TaxID2Taxon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaxID2TaxonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GTaxonServer).TaxID2Taxon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GTaxon_TaxID2Taxon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GTaxonServer).TaxID2Taxon(ctx, req.(*TaxID2TaxonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GTaxon_TaxID2TaxonStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TaxID2TaxonRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GTaxonServer).TaxID2TaxonStream(m, &grpc.GenericServerStream[TaxID2TaxonRequest, TaxID2TaxonItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_TaxID2TaxonStreamServer = grpc.ServerStreamingServer[TaxID2TaxonItem]
+
+func _GTaxon_Name2TaxID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Name2TaxIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GTaxonServer).Name2TaxID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GTaxon_Name2TaxID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GTaxonServer).Name2TaxID(ctx, req.(*Name2TaxIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GTaxon_Name2TaxIDStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Name2TaxIDRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GTaxonServer).Name2TaxIDStream(m, &grpc.GenericServerStream[Name2TaxIDRequest, Name2TaxIDItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_Name2TaxIDStreamServer = grpc.ServerStreamingServer[Name2TaxIDItem]
+
+func _GTaxon_LCA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LCARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GTaxonServer).LCA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GTaxon_LCA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GTaxonServer).LCA(ctx, req.(*LCARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GTaxon_LCAStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LCARequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GTaxonServer).LCAStream(m, &grpc.GenericServerStream[LCARequest, LCAItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_LCAStreamServer = grpc.ServerStreamingServer[LCAItem]
+
+func _GTaxon_Lineage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LineageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GTaxonServer).Lineage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GTaxon_Lineage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GTaxonServer).Lineage(ctx, req.(*LineageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GTaxon_LineageStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LineageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GTaxonServer).LineageStream(m, &grpc.GenericServerStream[LineageRequest, LineageItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GTaxon_LineageStreamServer = grpc.ServerStreamingServer[LineageItem]
+
+// GTaxon_ServiceDesc is the grpc.ServiceDesc for GTaxon service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GTaxon_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gtaxon.GTaxon",
+	HandlerType: (*GTaxonServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Gi2TaxID",
+			Handler:    _GTaxon_Gi2TaxID_Handler,
+		},
+		{
+			MethodName: "Acc2TaxID",
+			Handler:    _GTaxon_Acc2TaxID_Handler,
+		},
+		{
+			MethodName: "TaxID2Taxon",
+			Handler:    _GTaxon_TaxID2Taxon_Handler,
+		},
+		{
+			MethodName: "Name2TaxID",
+			Handler:    _GTaxon_Name2TaxID_Handler,
+		},
+		{
+			MethodName: "LCA",
+			Handler:    _GTaxon_LCA_Handler,
+		},
+		{
+			MethodName: "Lineage",
+			Handler:    _GTaxon_Lineage_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Gi2TaxIDStream",
+			Handler:       _GTaxon_Gi2TaxIDStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Acc2TaxIDStream",
+			Handler:       _GTaxon_Acc2TaxIDStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "TaxID2TaxonStream",
+			Handler:       _GTaxon_TaxID2TaxonStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Name2TaxIDStream",
+			Handler:       _GTaxon_Name2TaxIDStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "LCAStream",
+			Handler:       _GTaxon_LCAStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "LineageStream",
+			Handler:       _GTaxon_LineageStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "taxon/pb/gtaxon.proto",
+}
@@ -0,0 +1,122 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// Methods below are shared by web server and local client.
+
+// QueryTaxid2Taxon querys Taxons by taxids
+func (t *Taxonomy) QueryTaxid2Taxon(taxids []string) (map[string]nodes.Taxon, error) {
+	for _, taxid := range taxids {
+		if !reDigitals.MatchString(taxid) {
+			return nil, invalidQuery("non-digital taxid given: "+taxid, taxid)
+		}
+	}
+	taxons := make(map[string]nodes.Taxon, len(taxids))
+	for _, taxid := range taxids {
+		taxons[taxid], _ = t.GetTaxonByTaxID(taxid)
+	}
+	return taxons, nil
+}
+
+// QueryChildren querys direct children of taxids, with scientific names and ranks
+func (t *Taxonomy) QueryChildren(taxids []string) (map[string][]nodes.LineageExItem, error) {
+	for _, taxid := range taxids {
+		if !reDigitals.MatchString(taxid) {
+			return nil, invalidQuery("non-digital taxid given: "+taxid, taxid)
+		}
+	}
+	children := make(map[string][]nodes.LineageExItem, len(taxids))
+	for _, taxid := range taxids {
+		ids := t.Children(taxid)
+		items := make([]nodes.LineageExItem, len(ids))
+		for i, id := range ids {
+			items[i].TaxId, _ = strconv.Atoi(id)
+			items[i].ScientificName = t.ScientificName(id)
+			items[i].Rank = t.Nodes[id].Rank
+		}
+		children[taxid] = items
+	}
+	return children, nil
+}
+
+// QueryLCA querys Lowest Common Ancestors, a query is comma-separated taxids
+func (t *Taxonomy) QueryLCA(queries []string) (map[string]nodes.Taxon, error) {
+	lcas := make(map[string]nodes.Taxon, len(queries))
+	for _, query := range queries {
+		taxids := strings.Split(query, ",")
+		lca, err := t.LCA(taxids)
+		if err != nil {
+			return nil, invalidQuery(fmt.Sprintf("%s: %s", query, err), query)
+		}
+		lcas[query], _ = t.GetTaxonByTaxID(lca.TaxID)
+	}
+	return lcas, nil
+}
+
+// QueryName2TaxID querys taxids and scientific names by names,
+// searching by regular expressions stops when ctx is done
+func (t *Taxonomy) QueryName2TaxID(ctx context.Context, useRegexp bool, nameClass string, names []string) (map[string][]TaxIDSciNameItem, error) {
+	results, err := t.QueryTaxIDByName(ctx, useRegexp, nameClass, t.Threads, names)
+	if err != nil {
+		return nil, err
+	}
+
+	name2taxidResults := make(map[string][]TaxIDSciNameItem, len(results))
+	for name, taxids := range results {
+		taxidsSciNameItems := make([]TaxIDSciNameItem, len(taxids))
+		for i, taxid := range taxids {
+			taxidInt, _ := strconv.Atoi(taxid)
+			taxidsSciNameItems[i] = TaxIDSciNameItem{
+				TaxID:          taxidInt,
+				ScientificName: t.ScientificName(taxid),
+			}
+		}
+		name2taxidResults[name] = taxidsSciNameItems
+	}
+	return name2taxidResults, nil
+}
+
+// QueryGi2Taxid querys taxids by GIs or accessions from database of the pool.
+// It stops when ctx is done, including waiting for database connection.
+func (t *Taxonomy) QueryGi2Taxid(ctx context.Context, bucket string, gis []string) ([]string, error) {
+	if t.pool == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDatabaseNotExists, bucket)
+	}
+	db, err := t.pool.GetDBContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.pool.ReleaseDB(db)
+
+	if IsAccBucket(bucket) {
+		return QueryAcc2Taxid(ctx, db, bucket, gis)
+	}
+	return QueryGi2Taxid(ctx, db, bucket, gis)
+}
@@ -0,0 +1,347 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server serves REST APIs with a Taxonomy, which could be reloaded
+// from database without restarting the server
+type Server struct {
+	dbFilePath  string
+	threads     int
+	poolTimeout time.Duration
+
+	mu      sync.RWMutex // guards current
+	current *generation  // nil before the first load finishes
+
+	statusMu sync.Mutex // guards status and progress
+	status   ReloadStatus
+	progress *LoadProgress
+}
+
+// generation is a loaded Taxonomy with counter of in-flight requests
+type generation struct {
+	t       *Taxonomy
+	release Release
+	wg      sync.WaitGroup
+}
+
+// Release describes the database being served
+type Release struct {
+	DBFile   string    `json:"db_file"`
+	ModTime  time.Time `json:"db_modified"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Nodes    int       `json:"nodes"`
+	Names    int       `json:"names"`
+}
+
+// Reload states
+const (
+	ReloadLoading   = "loading" // the first load
+	ReloadIdle      = "idle"
+	ReloadReloading = "reloading"
+	ReloadFailed    = "failed"
+)
+
+// ReloadStatus reports state and progress of the last (re)load,
+// and the release being served
+type ReloadStatus struct {
+	State    string                     `json:"state"`
+	Started  time.Time                  `json:"started"`
+	Finished time.Time                  `json:"finished"`
+	Error    string                     `json:"error,omitempty"`
+	Progress map[string]DatasetProgress `json:"progress"`
+	Release  Release                    `json:"release"`
+}
+
+// NewServer creates a Server with DBFilePath, Threads and PoolTimeout of opts,
+// database is not loaded until Reload is called
+func NewServer(opts ServerOptions) *Server {
+	return &Server{
+		dbFilePath:  opts.DBFilePath,
+		threads:     opts.Threads,
+		poolTimeout: time.Duration(opts.PoolTimeout) * time.Second,
+	}
+}
+
+// load opens a new DBPool and loads Taxonomy from it
+func (s *Server) load(progress *LoadProgress) (*generation, error) {
+	info, err := os.Stat(s.dbFilePath)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := NewDBPool(s.dbFilePath, s.threads)
+	if err != nil {
+		return nil, err
+	}
+	pool.SetWaitTimeout(s.poolTimeout)
+	t, err := LoadTaxonomyWithProgress(pool, progress)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	t.Threads = s.threads * 2
+
+	release := Release{
+		DBFile:   s.dbFilePath,
+		ModTime:  info.ModTime(),
+		LoadedAt: time.Now(),
+		Nodes:    len(t.Nodes),
+		Names:    len(t.Names),
+	}
+	return &generation{t: t, release: release}, nil
+}
+
+// acquire returns the generation in service, or nil if not loaded yet.
+// g.wg.Done() must be called after use
+func (s *Server) acquire() *generation {
+	s.mu.RLock()
+	g := s.current
+	if g != nil {
+		g.wg.Add(1)
+	}
+	s.mu.RUnlock()
+	return g
+}
+
+// Ready returns true if Taxonomy is loaded
+func (s *Server) Ready() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current != nil
+}
+
+// Reload builds new Taxonomy and DBPool in background, and atomically
+// swaps them in when ready. Old ones are closed after in-flight requests
+// on them finish. It also does the first load of a new Server.
+// It returns false if another (re)load is in progress.
+func (s *Server) Reload() bool {
+	progress := NewLoadProgress()
+
+	s.statusMu.Lock()
+	if s.status.State == ReloadReloading || s.status.State == ReloadLoading {
+		s.statusMu.Unlock()
+		return false
+	}
+	if s.Ready() {
+		s.status.State = ReloadReloading
+	} else {
+		s.status.State = ReloadLoading
+	}
+	s.status.Started = time.Now()
+	s.status.Error = ""
+	s.progress = progress
+	s.statusMu.Unlock()
+
+	go func() {
+		log.Infof("load database: %s", s.dbFilePath)
+		g, err := s.load(progress)
+		if err != nil {
+			log.Errorf("load database failed: %s", err)
+			s.statusMu.Lock()
+			s.status.State = ReloadFailed
+			s.status.Finished = time.Now()
+			s.status.Error = err.Error()
+			s.statusMu.Unlock()
+			return
+		}
+
+		s.mu.Lock()
+		old := s.current
+		s.current = g
+		s.mu.Unlock()
+		observeTaxonomy(g.t)
+
+		s.statusMu.Lock()
+		s.status.State = ReloadIdle
+		s.status.Finished = time.Now()
+		s.statusMu.Unlock()
+		log.Infof("load database: %s ... done", s.dbFilePath)
+		if old == nil {
+			return
+		}
+
+		// drain in-flight requests on the old one
+		old.wg.Wait()
+		old.t.Pool().Close()
+		log.Info("old database closed")
+	}()
+	return true
+}
+
+// Status returns reload status and the release being served
+func (s *Server) Status() ReloadStatus {
+	s.statusMu.Lock()
+	status := s.status
+	status.Progress = s.progress.Datasets()
+	s.statusMu.Unlock()
+
+	s.mu.RLock()
+	if s.current != nil {
+		status.Release = s.current.release
+	}
+	s.mu.RUnlock()
+	return status
+}
+
+// ReloadOnSignal reloads database on receiving SIGHUP
+func (s *Server) ReloadOnSignal() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	for range ch {
+		log.Info("SIGHUP received")
+		if !s.Reload() {
+			log.Warning("reload in progress, SIGHUP ignored")
+		}
+	}
+}
+
+// Close closes the database in service
+func (s *Server) Close() {
+	g := s.acquire()
+	if g == nil {
+		return
+	}
+	g.wg.Done()
+	g.wg.Wait()
+	g.t.Pool().Close()
+}
+
+// RegisterRoutes registers query handlers, /versions and health checking
+// handlers (/healthz, /readyz and /status) to router. Every query is served
+// by the Taxonomy in service when it arrives, or answered with 503 before loaded.
+func (s *Server) RegisterRoutes(router gin.IRoutes) {
+	for _, r := range routes {
+		handler := r.handler
+		router.Handle(r.method, r.path, func(c *gin.Context) {
+			g := s.acquire()
+			if g == nil {
+				respondError(c, newErrorMessage(c), errNotLoaded)
+				return
+			}
+			defer g.wg.Done()
+			c.Set(releaseKey, g.release)
+			handler(g.t, c)
+		})
+	}
+
+	router.GET("/versions", apiVersions)
+	router.GET("/healthz", s.healthz)
+	router.GET("/readyz", s.readyz)
+	router.GET("/status", s.serverStatus)
+}
+
+var errNotLoaded = &APIError{Code: CodeDatabaseNotReady, Message: "database not loaded yet, see /status for progress"}
+
+// healthz reports the process is alive
+func (s *Server) healthz(c *gin.Context) {
+	var msg MessageStatus
+	msg.Status = "OK"
+	c.JSON(http.StatusOK, msg)
+}
+
+// readyz reports whether Taxonomy is loaded and queries could be served
+func (s *Server) readyz(c *gin.Context) {
+	var msg MessageStatus
+	if !s.Ready() {
+		respondError(c, &msg, errNotLoaded)
+		return
+	}
+	msg.Status = "OK"
+	c.JSON(http.StatusOK, msg)
+}
+
+// MessageServerStatus is
+type MessageServerStatus struct {
+	MessageStatus
+
+	Ready  bool         `json:"ready"`
+	Reload ReloadStatus `json:"reload"`
+}
+
+// serverStatus reports loading progress and record counts of datasets
+func (s *Server) serverStatus(c *gin.Context) {
+	var msg MessageServerStatus
+	msg.Status = "OK"
+	msg.Ready = s.Ready()
+	msg.Reload = s.Status()
+	c.JSON(http.StatusOK, msg)
+}
+
+// RegisterAdminRoutes registers admin handlers to router,
+// requests should carry header "Authorization: Bearer <token>"
+func (s *Server) RegisterAdminRoutes(router gin.IRoutes, token string) {
+	auth := adminAuth(token)
+	router.GET("/admin/reload", auth, s.reloadStatus)
+	router.POST("/admin/reload", auth, s.reload)
+}
+
+// adminAuth checks the bearer token of admin requests
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			var msg MessageStatus
+			respondError(c, &msg, &APIError{Code: CodeUnauthorized, Message: "invalid admin token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// MessageReloadStatus is
+type MessageReloadStatus struct {
+	MessageStatus
+
+	Reload ReloadStatus `json:"reload"`
+}
+
+func (s *Server) reloadStatus(c *gin.Context) {
+	var msg MessageReloadStatus
+	msg.Status = "OK"
+	msg.Reload = s.Status()
+	c.JSON(http.StatusOK, msg)
+}
+
+func (s *Server) reload(c *gin.Context) {
+	var msg MessageReloadStatus
+	if !s.Reload() {
+		msg.Reload = s.Status()
+		respondError(c, &msg, &APIError{Code: CodeReloadInProgress, Message: "reload in progress"})
+		return
+	}
+	msg.Status = "OK"
+	msg.Message = "reload started"
+	msg.Reload = s.Status()
+	c.JSON(http.StatusAccepted, msg)
+}
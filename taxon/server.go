@@ -21,266 +21,406 @@
 package taxon
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
-	"regexp"
 	"runtime"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/boltdb/bolt"
 	"github.com/gin-gonic/gin"
-	"github.com/parnurzeal/gorequest"
 	"github.com/shenwei356/gtaxon/taxon/nodes"
 )
 
-var threadNum int
-
-// StartServer runs a web server for query
-func StartServer(dbFilePath string, port int, timeout int, threads int) {
-	runtime.GOMAXPROCS(threads)
-	threadNum = threads
-	pool = NewDBPool(dbFilePath, threads)
-
-	done := make(chan int)
-	go func() {
-		db := pool.GetDB()
-		defer pool.ReleaseDB(db)
-
-		log.Info("load all names ...")
-		names, err := LoadAllNames(db, "names")
-		checkError(err)
-		nodes.SetNames(names)
-		log.Info("load all names ... done")
-		done <- 1
-	}()
-
-	done1 := make(chan int)
-	go func() {
-		db := pool.GetDB()
-		defer pool.ReleaseDB(db)
-
-		log.Info("load all nodes ...")
-		nods, err := LoadAllNodes(db, "nodes")
-		nodes.SetNodes(nods)
-		checkError(err)
-		log.Info("load all nodes ... done")
-
-		done1 <- 1
-	}()
-
-	done2 := make(chan int)
-	go func() {
-		db := pool.GetDB()
-		defer pool.ReleaseDB(db)
-
-		log.Info("load all divisions ...")
-		divisions, err := LoadAllDivisions(db, "divisions")
-		nodes.SetDivisions(divisions)
-		checkError(err)
-		log.Info("load all divisions ... done")
-
-		done2 <- 1
-	}()
-
-	done3 := make(chan int)
-	go func() {
-		db := pool.GetDB()
-		defer pool.ReleaseDB(db)
-
-		log.Info("load all gencodes ...")
-		gencodes, err := LoadAllGenCodes(db, "gencodes")
-		nodes.SetGenCodes(gencodes)
-		checkError(err)
-		log.Info("load all gencodes ... done")
-
-		done3 <- 1
-	}()
-
-	<-done2
-	<-done3
-	<-done
-	<-done1
+// ServerOptions is options of StartServer
+type ServerOptions struct {
+	DBFilePath string
+	Port       int
+	GRPCPort   int // port of gRPC service, 0 for disabled
+	Threads    int // max number of database connection
+
+	// Timeout (seconds) of reading request, writing response and
+	// processing request, 0 for no limit
+	Timeout int
+	// PoolTimeout (seconds) is the max time waiting for a database connection,
+	// requests are answered with 503 after that. 0 for no limit
+	PoolTimeout int
+
+	// TLSCert and TLSKey are certificate and key files for serving HTTPS,
+	// plain HTTP is served if not given
+	TLSCert string
+	TLSKey  string
+	// TLSClientCA is the CA bundle verifying client certificates,
+	// clients without valid certificate are rejected if given
+	TLSClientCA string
+
+	// AdminToken is the bearer token for admin APIs,
+	// admin APIs are disabled if empty
+	AdminToken string
+
+	// Limits of API keys, request rates and batch sizes of query APIs
+	Limits LimitOptions
+}
 
-	gin.SetMode(gin.ReleaseMode)
-	router := gin.Default()
+// StartServer runs a web server for query. Database could be reloaded
+// by SIGHUP or admin API without restarting.
+func StartServer(opts ServerOptions) error {
+	runtime.GOMAXPROCS(opts.Threads)
+	if _, err := os.Stat(opts.DBFilePath); err != nil {
+		return err
+	}
+	limiter, err := NewLimiter(opts.Limits)
+	if err != nil {
+		return err
+	}
+	var tlsConfig *tls.Config
+	if opts.TLSCert != "" || opts.TLSKey != "" || opts.TLSClientCA != "" {
+		tlsConfig, err = newServerTLSConfig(opts.TLSCert, opts.TLSKey, opts.TLSClientCA)
+		if err != nil {
+			return err
+		}
+	}
 
-	router.GET("/gi2taxid", gi2taxid)
-	router.GET("/taxid2taxon", taxid2taxon)
-	router.GET("/name2taxid", name2taxid)
-	router.GET("/lca", lca)
+	// listen immediately, queries are answered with 503 until loaded
+	s := NewServer(opts)
+	defer s.Close()
+	s.Reload()
+	go s.ReloadOnSignal()
+
+	if opts.GRPCPort > 0 {
+		err = s.startGRPC(opts.GRPCPort, tlsConfig, limiter, time.Duration(opts.Timeout)*time.Second)
+		if err != nil {
+			return err
+		}
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	router, err := newRouter(s, opts, limiter)
+	if err != nil {
+		return err
+	}
+	if err = CheckOpenAPI(router.Routes()); err != nil {
+		log.Warning(err)
+	}
 
 	// router.Run(fmt.Sprintf(":%d", port))
-	s := &http.Server{
-		Addr:           fmt.Sprintf(":%d", port),
+	server := &http.Server{
+		Addr:           fmt.Sprintf(":%d", opts.Port),
 		Handler:        router,
-		ReadTimeout:    2 * time.Second,
-		WriteTimeout:   2 * time.Second,
+		ReadTimeout:    time.Duration(opts.Timeout) * time.Second,
+		WriteTimeout:   time.Duration(opts.Timeout) * time.Second,
 		MaxHeaderBytes: 1 << 20,
 	}
-	s.ListenAndServe()
+	if tlsConfig != nil {
+		server.TLSConfig = tlsConfig
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
 }
 
-// --------------------------------------------------------------------------
+// newRouter returns router with all routes of server, including admin APIs
+// if admin token is given, API docs and web UI
+func newRouter(s *Server, opts ServerOptions, limiter *Limiter) (*gin.Engine, error) {
+	router := gin.Default()
+	router.Use(MetricsMiddleware(), limiter.Middleware())
+	if opts.Timeout > 0 {
+		router.Use(timeoutMiddleware(time.Duration(opts.Timeout) * time.Second))
+	}
+	router.GET("/metrics", MetricsHandler())
+	s.RegisterRoutes(router)
+	if opts.AdminToken != "" {
+		s.RegisterAdminRoutes(router, opts.AdminToken)
+	}
+	if err := RegisterDocRoutes(router); err != nil {
+		return nil, err
+	}
+	RegisterUIRoutes(router)
+	return router, nil
+}
 
-// MessageLCAMap is
-type MessageLCAMap struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+// timeoutMiddleware sets deadline of request context,
+// so that queries stop when the time is up
+func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
 
-	LCA map[string]nodes.Taxon `json:"taxids2taxon"`
+// routes of query handlers, which should be described in openapi.json
+var routes = []struct {
+	method  string
+	path    string
+	handler func(t *Taxonomy, c *gin.Context)
+}{
+	{"GET", "/v1/gi2taxid", (*Taxonomy).gi2taxidV1},
+	{"GET", "/v1/taxid2taxon", (*Taxonomy).taxid2taxonV1},
+	{"GET", "/v1/name2taxid", (*Taxonomy).name2taxidV1},
+	{"GET", "/v1/lca", (*Taxonomy).lcaV1},
+	{"GET", "/v1/children", (*Taxonomy).childrenV1},
+
+	// POST with JSON body for large batches
+	{"POST", "/v1/gi2taxid", (*Taxonomy).gi2taxidV1},
+	{"POST", "/v1/taxid2taxon", (*Taxonomy).taxid2taxonV1},
+	{"POST", "/v1/name2taxid", (*Taxonomy).name2taxidV1},
+	{"POST", "/v1/lca", (*Taxonomy).lcaV1},
+	{"POST", "/v1/children", (*Taxonomy).childrenV1},
+
+	// legacy APIs, deprecated
+	{"GET", "/gi2taxid", deprecated((*Taxonomy).gi2taxid)},
+	{"GET", "/taxid2taxon", deprecated((*Taxonomy).taxid2taxon)},
+	{"GET", "/name2taxid", deprecated((*Taxonomy).name2taxid)},
+	{"GET", "/lca", deprecated((*Taxonomy).lca)},
+	{"GET", "/children", deprecated((*Taxonomy).childrenOf)},
+	{"POST", "/gi2taxid", deprecated((*Taxonomy).gi2taxid)},
+	{"POST", "/taxid2taxon", deprecated((*Taxonomy).taxid2taxon)},
+	{"POST", "/name2taxid", deprecated((*Taxonomy).name2taxid)},
+	{"POST", "/lca", deprecated((*Taxonomy).lca)},
+	{"POST", "/children", deprecated((*Taxonomy).childrenOf)},
 }
 
-func lca(c *gin.Context) {
-	var msg MessageLCAMap
+// RegisterRoutes registers query handlers of the Taxonomy to router,
+// and /versions reporting supported API versions
+func (t *Taxonomy) RegisterRoutes(router gin.IRoutes) {
+	for _, r := range routes {
+		handler := r.handler
+		router.Handle(r.method, r.path, func(c *gin.Context) {
+			handler(t, c)
+		})
+	}
+	router.GET("/versions", apiVersions)
+}
 
-	c.Request.ParseForm()
-	queries := c.Request.Form["taxids"]
+// bindJSON decodes JSON body of POST request to v
+func bindJSON(c *gin.Context, v interface{}) error {
+	if err := json.NewDecoder(c.Request.Body).Decode(v); err != nil {
+		return &APIError{Code: CodeInvalidBody, Message: fmt.Sprintf("invalid JSON body: %s", err)}
+	}
+	return nil
+}
 
-	if queries == nil {
-		msg.Status = "FAILED"
-		msg.Message = "no Taxids given"
-		c.JSON(http.StatusOK, msg)
-		return
+// checkQueries records batch size of request, and checks that
+// n queries are given and not exceeding the max batch size
+func checkQueries(c *gin.Context, n int, what string) error {
+	setBatchSize(c, n)
+	if n == 0 {
+		return &APIError{Code: CodeMissingQuery, Message: "no " + what + " given"}
 	}
+	return checkBatchSize(c, n)
+}
 
-	db := pool.GetDB()
-	defer pool.ReleaseDB(db)
+// remoteURL returns URL of the API path on server. baseURL is full URL
+// of server, including scheme, host, port and optional path prefix,
+// e.g., "https://example.org:8443/gtaxon"
+func remoteURL(baseURL string, path string) string {
+	return strings.TrimRight(strings.TrimSpace(baseURL), "/") + path
+}
 
-	result := make(map[string]nodes.Node)
-	for _, query := range queries {
-		taxids := strings.Split(query, ",")
-		lca, err := nodes.LCA(nodes.Nodes, taxids)
-		if err != nil {
-			msg.Status = "FAILED"
-			msg.Message = fmt.Sprintf("error: %s", err)
-			c.JSON(http.StatusOK, msg)
-			return
-		}
-		result[query] = lca
-	}
+// --------------------------------------------------------------------------
 
-	msg.Status = "OK"
-	msg.Message = fmt.Sprintf("sum: %d", len(queries))
-	msg.LCA = make(map[string]nodes.Taxon, len(result))
-	for k, node := range result {
-		msg.LCA[k], _ = nodes.GetTaxonByTaxID(node.TaxID)
-	}
+// MessageLCAMap is
+type MessageLCAMap struct {
+	MessageStatus
 
-	c.JSON(http.StatusOK, msg)
+	LCA map[string]nodes.Taxon `json:"taxids2taxon"`
+}
+
+// LCARequest is JSON body of POST /lca,
+// each query is comma-separated taxids
+type LCARequest struct {
+	TaxIDs []string `json:"taxids"`
 }
 
-// RemoteQueryLCA is
-func RemoteQueryLCA(host string, port int, queries []string) MessageLCAMap {
-	host = strings.TrimSpace(host)
-	var url string
-	if regexp.MustCompile("^http://").MatchString(host) {
-		url = fmt.Sprintf("%s:%d/lca", host, port)
+// readLCARequest reads queries from JSON body of POST request or URL of GET request
+func readLCARequest(c *gin.Context) (LCARequest, error) {
+	var req LCARequest
+	if c.Request.Method == "POST" {
+		if err := bindJSON(c, &req); err != nil {
+			return req, err
+		}
 	} else {
-		url = fmt.Sprintf("http://%s:%d/lca", host, port)
+		c.Request.ParseForm()
+		req.TaxIDs = c.Request.Form["taxids"]
 	}
+	return req, checkQueries(c, len(req.TaxIDs), "Taxids")
+}
 
-	request := gorequest.New().Get(url)
+func (t *Taxonomy) lca(c *gin.Context) {
+	var msg MessageLCAMap
 
-	for _, query := range queries {
-		request = request.Param("taxids", query)
+	req, err := readLCARequest(c)
+	if err != nil {
+		respondError(c, &msg, err)
+		return
 	}
+	queries := req.TaxIDs
 
-	_, body, errs := request.End()
-	if errs != nil {
-		log.Error(errs)
-		os.Exit(-1)
+	if wantsNDJSON(c) {
+		streamNDJSON(c, &msg, queries, func(queries []string) ([]interface{}, error) {
+			lcas, err := t.QueryLCA(queries)
+			if err != nil {
+				return nil, err
+			}
+			items := make([]interface{}, len(queries))
+			for i, query := range queries {
+				items[i] = LCAItem{Query: query, LCA: lcas[query]}
+			}
+			return items, nil
+		})
+		return
 	}
 
-	var result MessageLCAMap
-	err := json.Unmarshal([]byte(body), &result)
-	checkError(err)
+	lcas, err := t.QueryLCA(queries)
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
 
-	return result
+	msg.Status = "OK"
+	msg.Message = fmt.Sprintf("sum: %d", len(queries))
+	msg.LCA = lcas
+
+	c.JSON(http.StatusOK, msg)
 }
 
 // --------------------------------------------------------------------------
 
 // MessageTaxid2TaxonMap is
 type MessageTaxid2TaxonMap struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	MessageStatus
 
 	Taxons map[string]nodes.Taxon `json:"taxid2taxon"`
 }
 
-func taxid2taxon(c *gin.Context) {
-	var msg MessageTaxid2TaxonMap
+// Taxid2TaxonRequest is JSON body of POST /taxid2taxon
+type Taxid2TaxonRequest struct {
+	TaxIDs []string `json:"taxids"`
+}
+
+// readTaxid2TaxonRequest reads taxids from JSON body of POST request or URL of GET request
+func readTaxid2TaxonRequest(c *gin.Context) (Taxid2TaxonRequest, error) {
+	var req Taxid2TaxonRequest
+	if c.Request.Method == "POST" {
+		if err := bindJSON(c, &req); err != nil {
+			return req, err
+		}
+	} else {
+		c.Request.ParseForm()
+		req.TaxIDs = c.Request.Form["taxid"]
+	}
+	return req, checkQueries(c, len(req.TaxIDs), "Taxids")
+}
 
-	c.Request.ParseForm()
-	taxids := c.Request.Form["taxid"]
+func (t *Taxonomy) taxid2taxon(c *gin.Context) {
+	var msg MessageTaxid2TaxonMap
 
-	if taxids == nil {
-		msg.Status = "FAILED"
-		msg.Message = "no Taxids given"
-		c.JSON(http.StatusOK, msg)
+	req, err := readTaxid2TaxonRequest(c)
+	if err != nil {
+		respondError(c, &msg, err)
 		return
 	}
+	taxids := req.TaxIDs
 
-	db := pool.GetDB()
-	defer pool.ReleaseDB(db)
+	if wantsNDJSON(c) {
+		streamNDJSON(c, &msg, taxids, func(taxids []string) ([]interface{}, error) {
+			taxons, err := t.QueryTaxid2Taxon(taxids)
+			if err != nil {
+				return nil, err
+			}
+			items := make([]interface{}, len(taxids))
+			for i, taxid := range taxids {
+				items[i] = Taxid2TaxonItem{Query: taxid, Taxon: taxons[taxid]}
+			}
+			return items, nil
+		})
+		return
+	}
 
-	bucket := "nodes"
-	nods, err := QueryNodeByTaxID(db, bucket, taxids)
+	taxons, err := t.QueryTaxid2Taxon(taxids)
 	if err != nil {
-		msg.Status = "FAILED"
-		msg.Message = fmt.Sprintf("error: %s", err)
-		c.JSON(http.StatusOK, msg)
+		respondError(c, &msg, err)
 		return
 	}
 	msg.Status = "OK"
-	msg.Message = fmt.Sprintf("sum: %d", len(nods))
-	msg.Taxons = make(map[string]nodes.Taxon)
-	for _, node := range nods {
-		taxon, _ := nodes.GetTaxonByTaxID(node.TaxID)
-		msg.Taxons[node.TaxID] = taxon
-	}
+	msg.Message = fmt.Sprintf("sum: %d", len(taxons))
+	msg.Taxons = taxons
 	c.JSON(http.StatusOK, msg)
 }
 
-// RemoteQueryTaxid2Taxon is
-func RemoteQueryTaxid2Taxon(host string, port int, taxids []string) MessageTaxid2TaxonMap {
-	host = strings.TrimSpace(host)
-	var url string
-	if regexp.MustCompile("^http://").MatchString(host) {
-		url = fmt.Sprintf("%s:%d/taxid2taxon", host, port)
+// --------------------------------------------------------------------------
+
+// MessageChildrenMap is
+type MessageChildrenMap struct {
+	MessageStatus
+
+	Children map[string][]nodes.LineageExItem `json:"children"`
+}
+
+// ChildrenRequest is JSON body of POST /children
+type ChildrenRequest struct {
+	TaxIDs []string `json:"taxids"`
+}
+
+// readChildrenRequest reads taxids from JSON body of POST request or URL of GET request
+func readChildrenRequest(c *gin.Context) (ChildrenRequest, error) {
+	var req ChildrenRequest
+	if c.Request.Method == "POST" {
+		if err := bindJSON(c, &req); err != nil {
+			return req, err
+		}
 	} else {
-		url = fmt.Sprintf("http://%s:%d/taxid2taxon", host, port)
+		c.Request.ParseForm()
+		req.TaxIDs = c.Request.Form["taxid"]
 	}
+	return req, checkQueries(c, len(req.TaxIDs), "Taxids")
+}
 
-	request := gorequest.New().Get(url)
+func (t *Taxonomy) childrenOf(c *gin.Context) {
+	var msg MessageChildrenMap
 
-	for _, taxid := range taxids {
-		request = request.Param("taxid", taxid)
+	req, err := readChildrenRequest(c)
+	if err != nil {
+		respondError(c, &msg, err)
+		return
 	}
+	taxids := req.TaxIDs
 
-	_, body, errs := request.End()
-	if errs != nil {
-		log.Error(errs)
-		os.Exit(-1)
+	if wantsNDJSON(c) {
+		streamNDJSON(c, &msg, taxids, func(taxids []string) ([]interface{}, error) {
+			children, err := t.QueryChildren(taxids)
+			if err != nil {
+				return nil, err
+			}
+			items := make([]interface{}, len(taxids))
+			for i, taxid := range taxids {
+				items[i] = ChildrenItem{Query: taxid, Children: children[taxid]}
+			}
+			return items, nil
+		})
+		return
 	}
 
-	var result MessageTaxid2TaxonMap
-	err := json.Unmarshal([]byte(body), &result)
-	checkError(err)
-
-	return result
+	children, err := t.QueryChildren(taxids)
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
+	msg.Status = "OK"
+	msg.Message = fmt.Sprintf("sum: %d", len(children))
+	msg.Children = children
+	c.JSON(http.StatusOK, msg)
 }
 
 // --------------------------------------------------------------------------
 
 // MssageName2TaxIDMap is
 type MssageName2TaxIDMap struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	MessageStatus
 
 	TaxIDs map[string][]TaxIDSciNameItem `json:"name2taxid"`
 }
@@ -291,187 +431,152 @@ type TaxIDSciNameItem struct {
 	ScientificName string
 }
 
-func name2taxid(c *gin.Context) {
-	var msg MssageName2TaxIDMap
-
-	c.Request.ParseForm()
-	useRegexp := false
-	if c.Query("regexp") != "" {
-		useRegexp = true
-	}
-	nameClass := ""
-	if c.Query("class") != "" {
-		nameClass = c.Query("class")
-	}
-	names := c.Request.Form["name"]
+// Name2TaxIDRequest is JSON body of POST /name2taxid
+type Name2TaxIDRequest struct {
+	Names  []string `json:"names"`
+	Regexp bool     `json:"regexp"`
+	Class  string   `json:"class"`
+}
 
-	if names == nil {
-		msg.Status = "FAILED"
-		msg.Message = "no names given"
-		c.JSON(http.StatusOK, msg)
-		return
+// readName2TaxIDRequest reads names and options from JSON body of POST request or URL of GET request
+func readName2TaxIDRequest(c *gin.Context) (Name2TaxIDRequest, error) {
+	var req Name2TaxIDRequest
+	if c.Request.Method == "POST" {
+		if err := bindJSON(c, &req); err != nil {
+			return req, err
+		}
+	} else {
+		c.Request.ParseForm()
+		req.Regexp = c.Query("regexp") != ""
+		req.Class = c.Query("class")
+		req.Names = c.Request.Form["name"]
 	}
+	return req, checkQueries(c, len(req.Names), "names")
+}
 
-	db := pool.GetDB()
-	defer pool.ReleaseDB(db)
+func (t *Taxonomy) name2taxid(c *gin.Context) {
+	var msg MssageName2TaxIDMap
 
-	bucket := "names"
-	results, err := QueryTaxIDByName(db, bucket, useRegexp, nameClass, threadNum*2, names)
+	req, err := readName2TaxIDRequest(c)
 	if err != nil {
-		msg.Status = "FAILED"
-		msg.Message = fmt.Sprintf("error: %s", err)
-		c.JSON(http.StatusOK, msg)
+		respondError(c, &msg, err)
 		return
 	}
-	msg.Status = "OK"
-	msg.Message = fmt.Sprintf("sum: %d", len(names))
+	useRegexp, nameClass, names := req.Regexp, req.Class, req.Names
 
-	name2taxidResults := make(map[string][]TaxIDSciNameItem, len(results))
-	for name, taxids := range results {
-		taxidsSciNameItems := make([]TaxIDSciNameItem, len(taxids))
-		for i, taxid := range taxids {
-			node := nodes.Nodes[taxid]
-			taxidInt, _ := strconv.Atoi(node.TaxID)
-			scientificName := ""
-			for _, nameItem := range nodes.Names[node.TaxID].Names {
-				if nameItem.NameClass == "scientific name" {
-					scientificName = nameItem.Name
-				}
+	if wantsNDJSON(c) {
+		streamNDJSON(c, &msg, names, func(names []string) ([]interface{}, error) {
+			results, err := t.QueryName2TaxID(c.Request.Context(), useRegexp, nameClass, names)
+			if err != nil {
+				return nil, err
 			}
-			taxidsSciNameItems[i] = TaxIDSciNameItem{
-				TaxID:          taxidInt,
-				ScientificName: scientificName,
+			items := make([]interface{}, len(names))
+			for i, name := range names {
+				items[i] = Name2TaxIDItem{Query: name, TaxIDs: results[name]}
 			}
-		}
-		name2taxidResults[name] = taxidsSciNameItems
-	}
-	msg.TaxIDs = name2taxidResults
-	c.JSON(http.StatusOK, msg)
-}
-
-// RemoteQueryName2TaxID is
-func RemoteQueryName2TaxID(host string, port int, useRegexp bool, nameClass string, names []string) MssageName2TaxIDMap {
-	host = strings.TrimSpace(host)
-	var url string
-	if regexp.MustCompile("^http://").MatchString(host) {
-		url = fmt.Sprintf("%s:%d/name2taxid", host, port)
-	} else {
-		url = fmt.Sprintf("http://%s:%d/name2taxid", host, port)
-	}
-
-	request := gorequest.New().Get(url)
-	if useRegexp {
-		request = request.Param("regexp", "1")
-	}
-	if nameClass != "" {
-		request = request.Param("class", nameClass)
-	}
-
-	for _, name := range names {
-		request = request.Param("name", name)
+			return items, nil
+		})
+		return
 	}
 
-	_, body, errs := request.End()
-	if errs != nil {
-		log.Error(errs)
-		os.Exit(-1)
+	results, err := t.QueryName2TaxID(c.Request.Context(), useRegexp, nameClass, names)
+	if err != nil {
+		respondError(c, &msg, err)
+		return
 	}
-
-	var result MssageName2TaxIDMap
-	err := json.Unmarshal([]byte(body), &result)
-	checkError(err)
-	return result
+	msg.Status = "OK"
+	msg.Message = fmt.Sprintf("sum: %d", len(names))
+	msg.TaxIDs = results
+	c.JSON(http.StatusOK, msg)
 }
 
 // --------------------------------------------------------------------------
 
 // MessageGI2TaxidMap is
 type MessageGI2TaxidMap struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	MessageStatus
 
 	Taxids map[string]string `json:"gi2taxid"`
 }
 
-func gi2taxid(c *gin.Context) {
-	var msg MessageGI2TaxidMap
+// Gi2TaxidRequest is JSON body of POST /gi2taxid
+type Gi2TaxidRequest struct {
+	DB  string   `json:"db"`
+	GIs []string `json:"gis"`
+}
 
-	// gi := c.Query("gi")  // single value
-	// multiple values
-	c.Request.ParseForm()
-	gis := c.Request.Form["gi"]
+// readGi2TaxidRequest reads GIs and db from JSON body of POST request or URL of GET request,
+// db defaults to gi_taxid_prot
+func readGi2TaxidRequest(c *gin.Context) (Gi2TaxidRequest, error) {
+	var req Gi2TaxidRequest
+	if c.Request.Method == "POST" {
+		if err := bindJSON(c, &req); err != nil {
+			return req, err
+		}
+	} else {
+		// gi := c.Query("gi")  // single value
+		// multiple values
+		c.Request.ParseForm()
+		req.GIs = c.Request.Form["gi"]
+		req.DB = c.Query("db")
+	}
+	if err := checkQueries(c, len(req.GIs), "GIs"); err != nil {
+		return req, err
+	}
 
-	if gis == nil {
-		msg.Status = "FAILED"
-		msg.Message = "no GIs given"
-		c.JSON(http.StatusOK, msg)
-		return
+	if req.DB == "" {
+		req.DB = "gi_taxid_prot"
 	}
+	if req.DB != "gi_taxid_prot" && req.DB != "gi_taxid_nucl" && !IsAccBucket(req.DB) {
+		return req, &APIError{
+			Code:    CodeInvalidDB,
+			Message: fmt.Sprintf("invalid db: %s. valid: gi_taxid_prot, gi_taxid_nucl, acc_taxid_prot or acc_taxid_nucl", req.DB),
+			Queries: []string{req.DB},
+		}
+	}
+	return req, nil
+}
 
-	bucket := c.DefaultQuery("db", "gi_taxid_prot")
-	if bucket != "gi_taxid_prot" && bucket != "gi_taxid_nucl" {
-		msg.Status = "FAILED"
-		msg.Message = fmt.Sprintf("invalid db: %s. valid: gi_taxid_prot or gi_taxid_nucl", bucket)
-		c.JSON(http.StatusOK, msg)
+func (t *Taxonomy) gi2taxid(c *gin.Context) {
+	var msg MessageGI2TaxidMap
+
+	req, err := readGi2TaxidRequest(c)
+	if err != nil {
+		respondError(c, &msg, err)
 		return
 	}
+	gis, bucket := req.GIs, req.DB
 
-	db := pool.GetDB()
-	defer pool.ReleaseDB(db)
+	if wantsNDJSON(c) {
+		streamNDJSON(c, &msg, gis, func(gis []string) ([]interface{}, error) {
+			taxids, err := t.QueryGi2Taxid(c.Request.Context(), bucket, gis)
+			if err != nil {
+				return nil, err
+			}
+			items := make([]interface{}, len(gis))
+			for i, gi := range gis {
+				items[i] = Gi2TaxidItem{Query: gi, TaxID: taxids[i]}
+			}
+			return items, nil
+		})
+		return
+	}
 
+	result, err := t.QueryGi2Taxid(c.Request.Context(), bucket, gis)
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
 	taxids := make(map[string]string, len(gis))
 	n := 0 // counter of seccessful query
-	err := db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(bucket))
-		if b == nil {
-			return fmt.Errorf("db not found: %s", bucket)
+	for i, gi := range gis {
+		if result[i] != "" {
+			n++
 		}
-		for _, gi := range gis {
-			taxid := string(b.Get([]byte(gi)))
-			if taxid != "" {
-				n++
-			}
-			taxids[gi] = taxid
-		}
-		return nil
-	})
-	if err != nil {
-		msg.Status = "FAILED"
-		msg.Message = fmt.Sprintf("error: %s", err)
-		c.JSON(http.StatusOK, msg)
-		return
+		taxids[gi] = result[i]
 	}
 	msg.Status = "OK"
 	msg.Message = fmt.Sprintf("sum: %d, found: %d", len(gis), n)
 	msg.Taxids = taxids
 	c.JSON(http.StatusOK, msg)
 }
-
-// RemoteQueryGi2Taxid query from remote server
-func RemoteQueryGi2Taxid(host string, port int, dbType string, gis []string) MessageGI2TaxidMap {
-	host = strings.TrimSpace(host)
-	var url string
-	if regexp.MustCompile("^http://").MatchString(host) {
-		url = fmt.Sprintf("%s:%d/gi2taxid", host, port)
-	} else {
-		url = fmt.Sprintf("http://%s:%d/gi2taxid", host, port)
-	}
-
-	request := gorequest.New().Get(url).Param("db", dbType)
-
-	for _, gi := range gis {
-		request = request.Param("gi", gi)
-	}
-
-	_, body, errs := request.End()
-	if errs != nil {
-		log.Error(errs)
-		os.Exit(-1)
-	}
-
-	var result MessageGI2TaxidMap
-	err := json.Unmarshal([]byte(body), &result)
-	checkError(err)
-
-	return result
-}
@@ -0,0 +1,147 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// snapshotMagic is the header of snapshot file, the last byte is format version
+var snapshotMagic = []byte("GTAXONSNAP\x01")
+
+// snapshotData is the content of snapshot file, encoded by gob
+type snapshotData struct {
+	Nodes     map[string]nodes.Node
+	Names     map[string]nodes.Name
+	Divisions map[string]nodes.Division
+	GenCodes  map[string]nodes.GenCode
+	NameIndex NameIndex
+}
+
+// SnapshotFile returns default snapshot file of database file
+func SnapshotFile(dbFilePath string) string {
+	return dbFilePath + ".snapshot"
+}
+
+// WriteSnapshot writes the in-memory Taxonomy, including NameIndex,
+// to a binary file, which could be loaded much faster than from database
+func (t *Taxonomy) WriteSnapshot(file string) error {
+	// write to temporary file first, not to leave a broken snapshot
+	tmp := file + ".tmp"
+	fh, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(fh, 1<<20)
+	data := snapshotData{
+		Nodes:     t.Nodes,
+		Names:     t.Names,
+		Divisions: t.Divisions,
+		GenCodes:  t.GenCodes,
+		NameIndex: t.NameIndex,
+	}
+	if _, err = w.Write(snapshotMagic); err == nil {
+		if err = gob.NewEncoder(w).Encode(data); err == nil {
+			err = w.Flush()
+		}
+	}
+	if errClose := fh.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// ReadSnapshot reads Taxonomy from snapshot file
+func ReadSnapshot(file string) (*Taxonomy, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	r := bufio.NewReaderSize(fh, 1<<20)
+
+	magic := make([]byte, len(snapshotMagic))
+	if _, err = io.ReadFull(r, magic); err != nil || string(magic) != string(snapshotMagic) {
+		return nil, fmt.Errorf("invalid or incompatible snapshot file: %s", file)
+	}
+	var data snapshotData
+	if err = gob.NewDecoder(r).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file: %s: %s", file, err)
+	}
+
+	t := NewTaxonomy(data.Nodes, data.Names, data.Divisions, data.GenCodes)
+	if data.NameIndex != nil {
+		t.NameIndex = data.NameIndex
+	}
+	return t, nil
+}
+
+// snapshotIsFresh returns true if snapshot file exists and is newer
+// than the database file
+func snapshotIsFresh(snapshotFile string, dbFilePath string) bool {
+	snapshot, err := os.Stat(snapshotFile)
+	if err != nil {
+		return false
+	}
+	db, err := os.Stat(dbFilePath)
+	if err != nil {
+		return false
+	}
+	return snapshot.ModTime().After(db.ModTime())
+}
+
+// loadSnapshot loads Taxonomy from snapshot of database of the pool,
+// errSnapshotStale is returned if snapshot is missing or older than database
+func loadSnapshot(pool *DBPool, progress *LoadProgress) (*Taxonomy, error) {
+	file := SnapshotFile(pool.Path())
+	if !snapshotIsFresh(file, pool.Path()) {
+		return nil, errSnapshotStale
+	}
+
+	log.Infof("load snapshot: %s ...", file)
+	t, err := ReadSnapshot(file)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("load snapshot: %s ... done", file)
+
+	progress.finish("names", len(t.Names), nil)
+	progress.finish("nodes", len(t.Nodes), nil)
+	progress.finish("divisions", len(t.Divisions), nil)
+	progress.finish("gencodes", len(t.GenCodes), nil)
+	progress.finish("name_index", len(t.NameIndex), nil)
+
+	t.pool = pool
+	return t, nil
+}
+
+var errSnapshotStale = errors.New("snapshot not exists or older than database")
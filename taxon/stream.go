@@ -0,0 +1,113 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// MIMENDJSON is the media type of streaming response,
+// i.e., newline-delimited JSON, one object per query
+const MIMENDJSON = "application/x-ndjson"
+
+// streamChunkSize is the number of queries resolved before
+// results being written and flushed to client
+const streamChunkSize = 1000
+
+// Gi2TaxidItem is one line of NDJSON response of /gi2taxid
+type Gi2TaxidItem struct {
+	Query string    `json:"query"`
+	TaxID string    `json:"taxid"`
+	Error *APIError `json:"error,omitempty"`
+}
+
+// ChildrenItem is one line of NDJSON response of /children
+type ChildrenItem struct {
+	Query    string                `json:"query"`
+	Children []nodes.LineageExItem `json:"children"`
+	Error    *APIError             `json:"error,omitempty"`
+}
+
+// Taxid2TaxonItem is one line of NDJSON response of /taxid2taxon
+type Taxid2TaxonItem struct {
+	Query string      `json:"query"`
+	Taxon nodes.Taxon `json:"taxon"`
+	Error *APIError   `json:"error,omitempty"`
+}
+
+// Name2TaxIDItem is one line of NDJSON response of /name2taxid
+type Name2TaxIDItem struct {
+	Query  string             `json:"query"`
+	TaxIDs []TaxIDSciNameItem `json:"taxids"`
+	Error  *APIError          `json:"error,omitempty"`
+}
+
+// LCAItem is one line of NDJSON response of /lca
+type LCAItem struct {
+	Query string      `json:"query"`
+	LCA   nodes.Taxon `json:"lca"`
+	Error *APIError   `json:"error,omitempty"`
+}
+
+// wantsNDJSON returns true if client accepts NDJSON response
+func wantsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), MIMENDJSON)
+}
+
+// streamNDJSON resolves queries chunk by chunk with fn, and writes
+// one JSON object per query as soon as the chunk is resolved.
+// Response header is written after the first chunk is resolved, so that
+// failures of it are responded as errors with msg and proper status code.
+// Once streaming, an object with only field "error" (error object) is written if fn fails.
+func streamNDJSON(c *gin.Context, msg interface{ setError(*APIError) }, queries []string, fn func(queries []string) ([]interface{}, error)) {
+	enc := json.NewEncoder(c.Writer)
+	var j int
+	for i := 0; i < len(queries); i += streamChunkSize {
+		j = i + streamChunkSize
+		if j > len(queries) {
+			j = len(queries)
+		}
+		items, err := fn(queries[i:j])
+		if err != nil {
+			if i == 0 {
+				respondError(c, msg, err)
+				return
+			}
+			enc.Encode(map[string]*APIError{"error": newAPIError(err)})
+			return
+		}
+		if i == 0 {
+			c.Header("Content-Type", MIMENDJSON)
+			c.Status(http.StatusOK)
+		}
+		for _, item := range items {
+			if err = enc.Encode(item); err != nil { // client gone
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
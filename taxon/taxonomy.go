@@ -0,0 +1,357 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// Taxonomy holds nodes, names, divisions, gencodes and derived indexes of
+// one release of NCBI taxonomy in memory. It should not be modified after
+// creation, so it's safe for concurrent queries.
+type Taxonomy struct {
+	Nodes     map[string]nodes.Node
+	Names     map[string]nodes.Name
+	Divisions map[string]nodes.Division
+	GenCodes  map[string]nodes.GenCode
+
+	// NameIndex maps lower-cased names to taxids
+	NameIndex NameIndex
+
+	// Threads is the number of goroutines for searching names by regexp
+	Threads int
+
+	// pool of database of gi_taxid and acc_taxid, optional
+	pool *DBPool
+
+	// children of taxids, built at the first query of children
+	childrenOnce sync.Once
+	children     map[string][]string
+}
+
+// NewTaxonomy creates a Taxonomy from data in memory
+func NewTaxonomy(nods map[string]nodes.Node, names map[string]nodes.Name,
+	divisions map[string]nodes.Division, gencodes map[string]nodes.GenCode) *Taxonomy {
+	return &Taxonomy{
+		Nodes:     nods,
+		Names:     names,
+		Divisions: divisions,
+		GenCodes:  gencodes,
+		NameIndex: NewNameIndex(names),
+		Threads:   runtime.NumCPU(),
+	}
+}
+
+// LoadTaxonomy loads Taxonomy from snapshot of the database (see WriteSnapshot)
+// if it is newer than the database file, otherwise from database by LoadTaxonomyFromDB.
+// The pool is also used for querying gi_taxid and acc_taxid.
+func LoadTaxonomy(pool *DBPool) (*Taxonomy, error) {
+	return LoadTaxonomyWithProgress(pool, nil)
+}
+
+// LoadTaxonomyWithProgress loads Taxonomy like LoadTaxonomy,
+// and records loading progress of datasets to progress, which could be nil.
+func LoadTaxonomyWithProgress(pool *DBPool, progress *LoadProgress) (*Taxonomy, error) {
+	t, err := loadSnapshot(pool, progress)
+	if err == nil {
+		return t, nil
+	}
+	if err != errSnapshotStale {
+		log.Warningf("%s, fall back to database", err)
+	}
+	return LoadTaxonomyFromDB(pool, progress)
+}
+
+// LoadTaxonomyFromDB loads names, nodes, divisions and gencodes from database
+// concurrently, and records loading progress to progress, which could be nil.
+func LoadTaxonomyFromDB(pool *DBPool, progress *LoadProgress) (*Taxonomy, error) {
+	var names map[string]nodes.Name
+	var nods map[string]nodes.Node
+	var divisions map[string]nodes.Division
+	var gencodes map[string]nodes.GenCode
+	var errNames, errNodes, errDivisions, errGencodes error
+
+	done := make(chan int, 1)
+	go func() {
+		db := pool.GetDB()
+		defer pool.ReleaseDB(db)
+
+		log.Info("load all names ...")
+		progress.start("names")
+		names, errNames = LoadAllNames(db, "names")
+		progress.finish("names", len(names), errNames)
+		if errNames == nil {
+			log.Info("load all names ... done")
+		}
+		done <- 1
+	}()
+
+	done1 := make(chan int, 1)
+	go func() {
+		db := pool.GetDB()
+		defer pool.ReleaseDB(db)
+
+		log.Info("load all nodes ...")
+		progress.start("nodes")
+		nods, errNodes = LoadAllNodes(db, "nodes")
+		progress.finish("nodes", len(nods), errNodes)
+		if errNodes == nil {
+			log.Info("load all nodes ... done")
+		}
+
+		done1 <- 1
+	}()
+
+	done2 := make(chan int, 1)
+	go func() {
+		db := pool.GetDB()
+		defer pool.ReleaseDB(db)
+
+		log.Info("load all divisions ...")
+		progress.start("divisions")
+		divisions, errDivisions = LoadAllDivisions(db, "divisions")
+		progress.finish("divisions", len(divisions), errDivisions)
+		if errDivisions == nil {
+			log.Info("load all divisions ... done")
+		}
+
+		done2 <- 1
+	}()
+
+	done3 := make(chan int, 1)
+	go func() {
+		db := pool.GetDB()
+		defer pool.ReleaseDB(db)
+
+		log.Info("load all gencodes ...")
+		progress.start("gencodes")
+		gencodes, errGencodes = LoadAllGenCodes(db, "gencodes")
+		progress.finish("gencodes", len(gencodes), errGencodes)
+		if errGencodes == nil {
+			log.Info("load all gencodes ... done")
+		}
+
+		done3 <- 1
+	}()
+
+	<-done2
+	<-done3
+	<-done
+	<-done1
+
+	for _, err := range []error{errNames, errNodes, errDivisions, errGencodes} {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	progress.start("name_index")
+	t := NewTaxonomy(nods, names, divisions, gencodes)
+	progress.finish("name_index", len(t.NameIndex), nil)
+	t.pool = pool
+	return t, nil
+}
+
+// Loading states of datasets
+const (
+	LoadPending = "pending"
+	LoadLoading = "loading"
+	LoadDone    = "done"
+	LoadFailed  = "failed"
+)
+
+// DatasetProgress is loading progress of one dataset
+type DatasetProgress struct {
+	State   string `json:"state"`
+	Records int    `json:"records"`
+	Error   string `json:"error,omitempty"`
+}
+
+// LoadProgress records loading progress of datasets of Taxonomy,
+// i.e., names, nodes, divisions, gencodes and name_index
+type LoadProgress struct {
+	mu       sync.Mutex
+	datasets map[string]DatasetProgress
+}
+
+// NewLoadProgress creates a LoadProgress with all datasets pending
+func NewLoadProgress() *LoadProgress {
+	datasets := make(map[string]DatasetProgress)
+	for _, dataset := range []string{"names", "nodes", "divisions", "gencodes", "name_index"} {
+		datasets[dataset] = DatasetProgress{State: LoadPending}
+	}
+	return &LoadProgress{datasets: datasets}
+}
+
+func (p *LoadProgress) start(dataset string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.datasets[dataset] = DatasetProgress{State: LoadLoading}
+	p.mu.Unlock()
+}
+
+func (p *LoadProgress) finish(dataset string, records int, err error) {
+	if p == nil {
+		return
+	}
+	progress := DatasetProgress{State: LoadDone, Records: records}
+	if err != nil {
+		progress = DatasetProgress{State: LoadFailed, Error: err.Error()}
+	}
+	p.mu.Lock()
+	p.datasets[dataset] = progress
+	p.mu.Unlock()
+}
+
+// Datasets returns a copy of progress of all datasets
+func (p *LoadProgress) Datasets() map[string]DatasetProgress {
+	datasets := make(map[string]DatasetProgress)
+	if p == nil {
+		return datasets
+	}
+	p.mu.Lock()
+	for dataset, progress := range p.datasets {
+		datasets[dataset] = progress
+	}
+	p.mu.Unlock()
+	return datasets
+}
+
+// Pool returns the database pool, which is nil for Taxonomy created by NewTaxonomy
+func (t *Taxonomy) Pool() *DBPool {
+	return t.pool
+}
+
+// ScientificName returns scientific name of a taxid
+func (t *Taxonomy) ScientificName(taxid string) string {
+	return t.Names[taxid].ScientificName()
+}
+
+// LCA returns the lowest common ancestor for a list of taxids
+func (t *Taxonomy) LCA(taxids []string) (nodes.Node, error) {
+	return nodes.LCA(t.Nodes, taxids)
+}
+
+// Children returns taxids of direct children of a taxid
+func (t *Taxonomy) Children(taxid string) []string {
+	t.childrenOnce.Do(func() {
+		t.children = make(map[string][]string, len(t.Nodes))
+		for id, node := range t.Nodes {
+			if node.PTaxID != id { // root is parent of itself
+				t.children[node.PTaxID] = append(t.children[node.PTaxID], id)
+			}
+		}
+		for _, ids := range t.children {
+			sort.Slice(ids, func(i, j int) bool {
+				a, _ := strconv.Atoi(ids[i])
+				b, _ := strconv.Atoi(ids[j])
+				return a < b
+			})
+		}
+	})
+	return t.children[taxid]
+}
+
+// GetTaxonByTaxID return Taxon obejct by taxid
+func (t *Taxonomy) GetTaxonByTaxID(taxid string) (nodes.Taxon, error) {
+	taxon := nodes.Taxon{}
+
+	node, ok := t.Nodes[taxid]
+	if !ok {
+		return taxon, fmt.Errorf("no Node matches taxid: %s", taxid)
+	}
+
+	name, ok := t.Names[taxid]
+	if !ok {
+		return taxon, fmt.Errorf("no Name matches taxid: %s", taxid)
+	}
+
+	division, ok := t.Divisions[node.DivisionID]
+	if !ok {
+		return taxon, fmt.Errorf("no Division matches division id: %s", node.DivisionID)
+	}
+
+	gencode, ok := t.GenCodes[node.GeneticCodeID]
+	if !ok {
+		return taxon, fmt.Errorf("no GenCode matches genetic code id: %s", node.GeneticCodeID)
+	}
+	mgencode := t.GenCodes[node.MitochondrialGCID]
+
+	taxon.TaxId, _ = strconv.Atoi(node.TaxID)
+	taxon.ParentTaxId, _ = strconv.Atoi(node.PTaxID)
+	taxon.Rank = node.Rank
+	taxon.Division = division.DivisionName
+
+	taxon.OtherNames = []nodes.TaxonNameItem{}
+	for _, nameItem := range name.Names {
+		if nameItem.NameClass == "scientific name" {
+			taxon.ScientificName = nameItem.Name
+		} else {
+			taxon.OtherNames = append(taxon.OtherNames, nodes.TaxonNameItem{
+				ClassCDE: nameItem.NameClass,
+				DispName: nameItem.Name,
+			})
+		}
+	}
+
+	gcid, _ := strconv.Atoi(gencode.GenCodeID)
+	taxon.GeneticCode = nodes.GeneticCodeItem{
+		GCId:   gcid,
+		GCName: gencode.Name,
+	}
+	mgcid, _ := strconv.Atoi(mgencode.GenCodeID)
+	taxon.MitoGeneticCode = nodes.MitoGeneticCodeItem{
+		MGCId:   mgcid,
+		MGCName: mgencode.Name,
+	}
+
+	ancestors := nodes.Ancestors(t.Nodes, node)
+	if len(ancestors) <= 2 {
+		return taxon, nil
+	}
+	lineageExItems := make([]nodes.LineageExItem, len(ancestors)-2)
+	LineageNameSlice := make([]string, len(ancestors)-2)
+	j := 0
+	for i := len(ancestors) - 2; i >= 1; i-- { // exclude root node and itself
+		anc := ancestors[i]
+		taxidInt, _ := strconv.Atoi(anc.TaxID)
+		scientificName := t.ScientificName(anc.TaxID)
+		lineageExItems[j] = nodes.LineageExItem{
+			TaxId:          taxidInt,
+			ScientificName: scientificName,
+			Rank:           anc.Rank,
+		}
+		LineageNameSlice[j] = scientificName
+		j++
+	}
+	taxon.Lineage = strings.Join(LineageNameSlice, "; ")
+	taxon.LineageEx = lineageExItems
+	return taxon, nil
+}
@@ -0,0 +1,174 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// newTestTaxonomy returns a small Taxonomy:
+//
+//	1 root
+//	├── 2 Bacteria
+//	│   └── 562 Escherichia coli
+//	└── 2759 Eukaryota
+//	    └── 9604 Hominidae
+//	        ├── 9606 Homo sapiens
+//	        └── 9598 Pan troglodytes
+func newTestTaxonomy() *Taxonomy {
+	nods := make(map[string]nodes.Node)
+	names := make(map[string]nodes.Name)
+	for _, n := range []struct {
+		taxid, parent, rank, division, name string
+		others                              []nodes.NameItem
+	}{
+		{"1", "1", "no rank", "8", "root", nil},
+		{"2", "1", "superkingdom", "0", "Bacteria", nil},
+		{"562", "2", "species", "0", "Escherichia coli", nil},
+		{"2759", "1", "superkingdom", "8", "Eukaryota", nil},
+		{"9604", "2759", "family", "2", "Hominidae", nil},
+		{"9606", "9604", "species", "5", "Homo sapiens",
+			[]nodes.NameItem{{Name: "human", NameClass: "genbank common name"}}},
+		{"9598", "9604", "species", "2", "Pan troglodytes",
+			[]nodes.NameItem{{Name: "chimpanzee", NameClass: "genbank common name"}}},
+	} {
+		nods[n.taxid] = nodes.Node{TaxID: n.taxid, PTaxID: n.parent, Rank: n.rank,
+			DivisionID: n.division, GeneticCodeID: "1", MitochondrialGCID: "2"}
+		items := append([]nodes.NameItem{{Name: n.name, NameClass: "scientific name"}}, n.others...)
+		names[n.taxid] = nodes.Name{TaxID: n.taxid, Names: items}
+	}
+	divisions := map[string]nodes.Division{
+		"0": {DivisionID: "0", DivisionName: "Bacteria"},
+		"2": {DivisionID: "2", DivisionName: "Mammals"},
+		"5": {DivisionID: "5", DivisionName: "Primates"},
+		"8": {DivisionID: "8", DivisionName: "Unassigned"},
+	}
+	gencodes := map[string]nodes.GenCode{
+		"1": {GenCodeID: "1", Name: "Standard"},
+		"2": {GenCodeID: "2", Name: "Vertebrate Mitochondrial"},
+	}
+	return NewTaxonomy(nods, names, divisions, gencodes)
+}
+
+func TestTaxonomyLCA(t *testing.T) {
+	tx := newTestTaxonomy()
+	for query, want := range map[string]int{
+		"9606,9598":       9604,
+		"9606,9604":       9604,
+		"9606,562":        1,
+		"9606,9598,12345": 9604, // missing taxids are ignored
+	} {
+		lcas, err := tx.QueryLCA([]string{query})
+		if err != nil {
+			t.Errorf("%s: %s", query, err)
+			continue
+		}
+		if got := lcas[query].TaxId; got != want {
+			t.Errorf("LCA of %s: got %d, want %d", query, got, want)
+		}
+	}
+
+	for _, query := range []string{"9606", "12345,54321"} {
+		_, err := tx.QueryLCA([]string{query})
+		if !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("LCA of %s: got error %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
+
+func TestTaxonomyTaxid2Taxon(t *testing.T) {
+	tx := newTestTaxonomy()
+	taxons, err := tx.QueryTaxid2Taxon([]string{"9606", "12345"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	human := taxons["9606"]
+	if human.TaxId != 9606 || human.ParentTaxId != 9604 || human.ScientificName != "Homo sapiens" ||
+		human.Rank != "species" || human.Division != "Primates" {
+		t.Errorf("unexpected Taxon of 9606: %+v", human)
+	}
+	if human.GeneticCode.GCId != 1 || human.MitoGeneticCode.MGCId != 2 {
+		t.Errorf("unexpected genetic codes of 9606: %+v, %+v", human.GeneticCode, human.MitoGeneticCode)
+	}
+	if human.Lineage != "Eukaryota; Hominidae" {
+		t.Errorf("unexpected lineage of 9606: %s", human.Lineage)
+	}
+	wantOthers := []nodes.TaxonNameItem{{ClassCDE: "genbank common name", DispName: "human"}}
+	if !reflect.DeepEqual(human.OtherNames, wantOthers) {
+		t.Errorf("unexpected other names of 9606: %+v", human.OtherNames)
+	}
+	if taxons["12345"].TaxId != 0 {
+		t.Errorf("missing taxid found: %+v", taxons["12345"])
+	}
+
+	_, err = tx.QueryTaxid2Taxon([]string{"9606", "abc"})
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) || !errors.Is(err, ErrInvalidQuery) ||
+		!reflect.DeepEqual(queryErr.Queries, []string{"abc"}) {
+		t.Errorf("got error %v, want ErrInvalidQuery of abc", err)
+	}
+}
+
+func TestTaxonomyName2TaxID(t *testing.T) {
+	tx := newTestTaxonomy()
+	ctx := context.Background()
+	tests := []struct {
+		useRegexp bool
+		nameClass string
+		query     string
+		want      []int
+	}{
+		{false, "", "Homo sapiens", []int{9606}},
+		{false, "", "human", []int{9606}},
+		{false, "scientific name", "human", nil},
+		{false, "", "homo sapiens", nil}, // case sensitive
+		{true, "", "^Homo", []int{9606}},
+		{true, "", "^H", []int{9604, 9606}},
+		{true, "genbank common name", "^(human|chimpanzee)$", []int{9598, 9606}},
+	}
+	for _, test := range tests {
+		results, err := tx.QueryName2TaxID(ctx, test.useRegexp, test.nameClass, []string{test.query})
+		if err != nil {
+			t.Errorf("%s: %s", test.query, err)
+			continue
+		}
+		var got []int
+		for _, item := range results[test.query] {
+			got = append(got, item.TaxID)
+		}
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("taxids of %q (regexp: %v, class: %q): got %v, want %v",
+				test.query, test.useRegexp, test.nameClass, got, test.want)
+		}
+	}
+
+	if _, err := tx.QueryName2TaxID(ctx, true, "", []string{"("}); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("got error %v of invalid regexp, want ErrInvalidQuery", err)
+	}
+}
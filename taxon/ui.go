@@ -20,11 +20,28 @@
 
 package taxon
 
-import "os"
+import (
+	"embed"
+	"io/fs"
+	"net/http"
 
-func checkError(err error) {
-	if err != nil {
-		log.Error(err)
-		os.Exit(-1)
-	}
+	"github.com/gin-gonic/gin"
+)
+
+// uiFiles are static files of web UI. They have no external dependencies,
+// so the UI works without internet access.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// RegisterUIRoutes registers web UI at /ui/, and redirects / to it
+func RegisterUIRoutes(router gin.IRoutes) {
+	files, _ := fs.Sub(uiFiles, "ui")
+	fileServer := http.StripPrefix("/ui", http.FileServer(http.FS(files)))
+	router.GET("/ui/*filepath", func(c *gin.Context) {
+		fileServer.ServeHTTP(c.Writer, c.Request)
+	})
+	router.GET("/", func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, "/ui/")
+	})
 }
@@ -0,0 +1,287 @@
+// Web UI of gtaxon server. It only uses the REST API v1 of the server,
+// and has no external dependencies, so it works without internet access.
+(function () {
+  "use strict";
+
+  var $ = function (id) { return document.getElementById(id); };
+
+  // ----------------------------------------------------------------------
+  // API
+
+  var apiKey = $("apikey");
+  apiKey.value = localStorage.getItem("gtaxon-api-key") || "";
+  apiKey.addEventListener("change", function () {
+    localStorage.setItem("gtaxon-api-key", apiKey.value);
+  });
+
+  // api posts JSON body to a query API of API v1, and returns results
+  // in the order of queries. Pages are under /ui/, so APIs are at ../v1/
+  function api(path, body) {
+    var headers = { "Content-Type": "application/json" };
+    if (apiKey.value) {
+      headers["X-API-Key"] = apiKey.value;
+    }
+    return fetch("../v1/" + path, { method: "POST", headers: headers, body: JSON.stringify(body) })
+      .then(function (resp) {
+        return resp.json().catch(function () {
+          throw new Error(resp.status + " " + resp.statusText);
+        });
+      })
+      .then(function (msg) {
+        if (msg.status !== "OK") {
+          throw new Error(msg.error ? msg.error.code + ": " + msg.error.message : "unknown error");
+        }
+        return msg.results;
+      });
+  }
+
+  // ----------------------------------------------------------------------
+  // helpers of DOM
+
+  function el(tag, attrs, children) {
+    var e = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (k) { e.setAttribute(k, attrs[k]); });
+    (children || []).forEach(function (c) {
+      e.appendChild(typeof c === "string" || typeof c === "number" ? document.createTextNode(String(c)) : c);
+    });
+    return e;
+  }
+
+  function taxonLink(taxid, text) {
+    return el("a", { href: "#/taxon/" + taxid }, [text || String(taxid)]);
+  }
+
+  function table(header, rows) {
+    return el("table", {}, [
+      el("thead", {}, [el("tr", {}, header.map(function (h) { return el("th", {}, [h]); }))]),
+      el("tbody", {}, rows.map(function (row) {
+        return el("tr", {}, row.map(function (c) { return el("td", {}, [c === undefined || c === null ? "" : c]); }));
+      }))
+    ]);
+  }
+
+  function show(container, node) {
+    container.innerHTML = "";
+    container.appendChild(node);
+  }
+
+  function showError(container, err) {
+    show(container, el("p", { "class": "error" }, [err.message]));
+  }
+
+  function loading(container) {
+    show(container, el("p", { "class": "muted" }, ["loading..."]));
+  }
+
+  // ----------------------------------------------------------------------
+  // search
+
+  $("search-form").addEventListener("submit", function (e) {
+    e.preventDefault();
+    var q = $("search-q").value.trim();
+    if (q) {
+      location.hash = "#/search?q=" + encodeURIComponent(q) + ($("search-regexp").checked ? "&regexp=1" : "");
+    }
+  });
+
+  function search(params) {
+    var q = params.get("q") || "", regexp = params.get("regexp") === "1";
+    $("search-q").value = q;
+    $("search-regexp").checked = regexp;
+    var out = $("search-result");
+    if (!q) {
+      out.innerHTML = "";
+      return;
+    }
+    loading(out);
+    api("name2taxid", { names: [q], regexp: regexp })
+      .then(function (results) {
+        if (!results[0].found) {
+          show(out, el("p", { "class": "muted" }, ["no taxon found"]));
+          return;
+        }
+        show(out, table(["TaxId", "Scientific name"], results[0].taxids.map(function (item) {
+          return [taxonLink(item.taxid), taxonLink(item.taxid, item.scientific_name)];
+        })));
+      })
+      .catch(function (err) { showError(out, err); });
+  }
+
+  // ----------------------------------------------------------------------
+  // taxon page
+
+  function taxonPage(taxid) {
+    var out = $("taxon-result");
+    loading(out);
+    Promise.all([
+      api("taxid2taxon", { taxids: [taxid] }),
+      api("children", { taxids: [taxid] })
+    ]).then(function (results) {
+      var taxon = results[0][0].taxon;
+      if (!taxon) {
+        show(out, el("p", { "class": "muted" }, ["TaxId " + taxid + " not found"]));
+        return;
+      }
+      var children = results[1][0].children;
+
+      var lineage = el("span", { "class": "lineage" });
+      taxon.lineage_ex.forEach(function (item, i) {
+        if (i > 0) {
+          lineage.appendChild(document.createTextNode("; "));
+        }
+        lineage.appendChild(taxonLink(item.taxid, item.scientific_name));
+        lineage.appendChild(el("span", { "class": "rank", title: "rank" }, [" " + item.rank]));
+      });
+
+      var gc = taxon.genetic_code, mgc = taxon.mito_genetic_code;
+      var page = el("div", {}, [
+        el("h2", {}, [taxon.scientific_name + " ", el("span", { "class": "rank" }, [taxon.rank])]),
+        el("dl", {}, [
+          el("dt", {}, ["TaxId"]), el("dd", {}, [String(taxon.taxid)]),
+          el("dt", {}, ["Rank"]), el("dd", {}, [taxon.rank]),
+          el("dt", {}, ["Parent"]), el("dd", {}, [taxonLink(taxon.parent_taxid)]),
+          el("dt", {}, ["Division"]), el("dd", {}, [taxon.division]),
+          el("dt", {}, ["Genetic code"]), el("dd", {}, [gc.id + " (" + gc.name + ")"]),
+          el("dt", {}, ["Mitochondrial genetic code"]), el("dd", {}, [mgc.id + " (" + mgc.name + ")"]),
+          el("dt", {}, ["Lineage"]), el("dd", {}, [lineage])
+        ]),
+        el("h3", {}, ["Names"]),
+        table(["Name", "Class"], [[taxon.scientific_name, "scientific name"]].concat(
+          taxon.other_names.map(function (n) { return [n.name, n.name_class]; }))),
+        el("h3", {}, ["Children (" + children.length + ")"]),
+        children.length === 0 ? el("p", { "class": "muted" }, ["none"]) :
+          table(["TaxId", "Scientific name", "Rank"], children.map(function (c) {
+            return [taxonLink(c.taxid), taxonLink(c.taxid, c.scientific_name), c.rank];
+          }))
+      ]);
+      show(out, page);
+    }).catch(function (err) { showError(out, err); });
+  }
+
+  // ----------------------------------------------------------------------
+  // batch tools
+
+  var tools = {
+    lca: {
+      header: ["Query", "LCA TaxId", "LCA scientific name", "LCA rank"],
+      run: function (queries) {
+        queries = queries.map(function (q) { return q.split(/[\s,]+/).filter(Boolean).join(","); });
+        return api("lca", { taxids: queries }).then(function (results) {
+          return results.map(function (r) {
+            var t = r.taxon || {};
+            return [r.query, t.taxid ? taxonLink(t.taxid) : "", t.scientific_name, t.rank];
+          });
+        });
+      }
+    },
+    name2taxid: {
+      header: ["Query", "TaxIds", "Scientific names"],
+      run: function (queries) {
+        return api("name2taxid", { names: queries }).then(function (results) {
+          return results.map(function (r) {
+            return [r.query,
+              r.taxids.map(function (i) { return i.taxid; }).join(","),
+              r.taxids.map(function (i) { return i.scientific_name; }).join(",")];
+          });
+        });
+      }
+    },
+    taxid2taxon: {
+      header: ["Query", "Scientific name", "Rank", "Lineage"],
+      run: function (queries) {
+        return api("taxid2taxon", { taxids: queries }).then(function (results) {
+          return results.map(function (r) {
+            var t = r.taxon;
+            return t ? [taxonLink(r.query), t.scientific_name, t.rank, t.lineage] : [r.query, "", "", ""];
+          });
+        });
+      }
+    },
+    gi2taxid: {
+      header: ["Query", "TaxId"],
+      run: function (queries) {
+        return api("gi2taxid", { db: $("tools-db").value, gis: queries }).then(function (results) {
+          return results.map(function (r) {
+            return [r.query, r.found ? taxonLink(r.taxid) : ""];
+          });
+        });
+      }
+    }
+  };
+
+  var lastResult = null;
+
+  function updateToolsForm() {
+    $("tools-db-label").style.display = $("tools-mode").value === "gi2taxid" ? "" : "none";
+  }
+  $("tools-mode").addEventListener("change", updateToolsForm);
+  updateToolsForm();
+
+  $("tools-form").addEventListener("submit", function (e) {
+    e.preventDefault();
+    var tool = tools[$("tools-mode").value], out = $("tools-result");
+    var queries = $("tools-input").value.split(/\r?\n/)
+      .map(function (q) { return q.trim(); })
+      .filter(Boolean);
+    if (queries.length === 0) {
+      return;
+    }
+    lastResult = null;
+    $("tools-download").disabled = true;
+    loading(out);
+    tool.run(queries).then(function (rows) {
+      lastResult = { header: tool.header, rows: rows };
+      $("tools-download").disabled = false;
+      show(out, table(tool.header, rows));
+    }).catch(function (err) { showError(out, err); });
+  });
+
+  $("tools-download").addEventListener("click", function () {
+    if (!lastResult) {
+      return;
+    }
+    var text = function (c) {
+      return (c && c.textContent !== undefined ? c.textContent : c === undefined || c === null ? "" : String(c))
+        .replace(/[\t\n]/g, " ");
+    };
+    var lines = [lastResult.header.join("\t")].concat(lastResult.rows.map(function (row) {
+      return row.map(text).join("\t");
+    }));
+    var a = el("a", {
+      href: URL.createObjectURL(new Blob([lines.join("\n") + "\n"], { type: "text/tab-separated-values" })),
+      download: "gtaxon-" + $("tools-mode").value + ".tsv"
+    });
+    document.body.appendChild(a);
+    a.click();
+    document.body.removeChild(a);
+  });
+
+  // ----------------------------------------------------------------------
+  // routing by location hash: #/search?q=, #/taxon/<taxid>, #/tools
+
+  function route() {
+    var hash = location.hash.replace(/^#\/?/, "");
+    var i = hash.indexOf("?");
+    var path = i < 0 ? hash : hash.slice(0, i);
+    var params = new URLSearchParams(i < 0 ? "" : hash.slice(i + 1));
+    var parts = path.split("/");
+    var page = parts[0] || "search";
+
+    document.querySelectorAll(".page").forEach(function (p) { p.classList.remove("active"); });
+    switch (page) {
+      case "taxon":
+        $("page-taxon").classList.add("active");
+        taxonPage(parts[1] || "1");
+        break;
+      case "tools":
+        $("page-tools").classList.add("active");
+        break;
+      default:
+        $("page-search").classList.add("active");
+        search(params);
+    }
+  }
+
+  window.addEventListener("hashchange", route);
+  route();
+})();
@@ -0,0 +1,530 @@
+// Copyright © 2016 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package taxon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shenwei356/gtaxon/taxon/nodes"
+)
+
+// API v1 is served at /v1/<endpoint>, e.g., /v1/taxid2taxon. Requests are
+// the same as legacy APIs, while responses share one envelope: status,
+// error object, echo of query, results in the order of queries and the
+// release of database. Fields of v1 are only added, never renamed or removed.
+// Legacy APIs without version prefix are kept as deprecated aliases.
+
+// APIVersion is the latest version of REST API
+const APIVersion = "v1"
+
+// APIVersions are versions of REST API supported by server, reported by /versions
+var APIVersions = []string{APIVersion}
+
+// MessageAPIVersions is the response of /versions
+type MessageAPIVersions struct {
+	MessageStatus
+
+	Versions []string `json:"versions"`
+}
+
+// apiVersions reports supported versions of REST API, clients use it to
+// choose the version, servers before API v1 answer it with 404
+func apiVersions(c *gin.Context) {
+	var msg MessageAPIVersions
+	msg.Status = "OK"
+	msg.Versions = APIVersions
+	c.JSON(http.StatusOK, msg)
+}
+
+// deprecated marks responses of legacy query APIs with header "Deprecation",
+// and "Link" to the successor in the latest API version
+func deprecated(handler func(t *Taxonomy, c *gin.Context)) func(t *Taxonomy, c *gin.Context) {
+	return func(t *Taxonomy, c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+APIVersion+c.FullPath()+`>; rel="successor-version"`)
+		handler(t, c)
+	}
+}
+
+const releaseKey = "gtaxon.release"
+
+// releaseOf returns the release of database serving the request,
+// nil if the Taxonomy is not served by Server
+func releaseOf(c *gin.Context) *Release {
+	if v, ok := c.Get(releaseKey); ok {
+		release := v.(Release)
+		return &release
+	}
+	return nil
+}
+
+// newErrorMessage returns response message for errors before queries
+// are handled, i.e., the envelope for API v1, or MessageStatus for others
+func newErrorMessage(c *gin.Context) interface{ setError(*APIError) } {
+	if strings.HasPrefix(c.FullPath(), "/"+APIVersion+"/") {
+		return &V1Envelope{}
+	}
+	return &MessageStatus{}
+}
+
+// --------------------------------------------------------------------------
+
+// V1Envelope holds common fields of responses of API v1
+type V1Envelope struct {
+	Status  string    `json:"status"`
+	Error   *APIError `json:"error,omitempty"`
+	Query   V1Query   `json:"query"`
+	Release *Release  `json:"release,omitempty"` // database being served
+}
+
+func (m *V1Envelope) setError(e *APIError) {
+	m.Status = "FAILED"
+	m.Error = e
+}
+
+// MessageStatus converts the envelope to MessageStatus of legacy APIs
+func (m *V1Envelope) MessageStatus() MessageStatus {
+	msg := MessageStatus{Status: m.Status, Error: m.Error}
+	if m.Error != nil {
+		msg.Message = m.Error.Message
+	}
+	return msg
+}
+
+// V1Query is the echo of query in responses of API v1
+type V1Query struct {
+	DB      string   `json:"db,omitempty"`     // only for gi2taxid
+	Regexp  bool     `json:"regexp,omitempty"` // only for name2taxid
+	Class   string   `json:"class,omitempty"`  // only for name2taxid
+	Queries []string `json:"queries"`
+}
+
+// V1Taxon is Taxon in API v1
+type V1Taxon struct {
+	TaxID           int             `json:"taxid"`
+	ScientificName  string          `json:"scientific_name"`
+	OtherNames      []V1OtherName   `json:"other_names"`
+	ParentTaxID     int             `json:"parent_taxid"`
+	Rank            string          `json:"rank"`
+	Division        string          `json:"division"`
+	GeneticCode     V1GeneticCode   `json:"genetic_code"`
+	MitoGeneticCode V1GeneticCode   `json:"mito_genetic_code"`
+	Lineage         string          `json:"lineage"`    // scientific names of ancestors, separated by "; "
+	LineageEx       []V1LineageNode `json:"lineage_ex"` // ancestors, from the child of root
+}
+
+// V1OtherName is
+type V1OtherName struct {
+	NameClass string `json:"name_class"`
+	Name      string `json:"name"`
+}
+
+// V1GeneticCode is
+type V1GeneticCode struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// V1LineageNode is
+type V1LineageNode struct {
+	TaxID          int    `json:"taxid"`
+	ScientificName string `json:"scientific_name"`
+	Rank           string `json:"rank"`
+}
+
+// V1TaxIDName is
+type V1TaxIDName struct {
+	TaxID          int    `json:"taxid"`
+	ScientificName string `json:"scientific_name"`
+}
+
+// Results of API v1, one per query. They are also lines of NDJSON response,
+// where a line with only error object is written if an error occurs.
+
+// V1TaxIDResult is result of /v1/gi2taxid
+type V1TaxIDResult struct {
+	Query string    `json:"query"`
+	Found bool      `json:"found"`
+	TaxID int       `json:"taxid"` // 0 if not found
+	Error *APIError `json:"error,omitempty"`
+}
+
+// V1TaxonResult is result of /v1/taxid2taxon and /v1/lca
+type V1TaxonResult struct {
+	Query string    `json:"query"`
+	Found bool      `json:"found"`
+	Taxon *V1Taxon  `json:"taxon"` // null if not found
+	Error *APIError `json:"error,omitempty"`
+}
+
+// V1Name2TaxIDResult is result of /v1/name2taxid
+type V1Name2TaxIDResult struct {
+	Query  string        `json:"query"`
+	Found  bool          `json:"found"`
+	TaxIDs []V1TaxIDName `json:"taxids"`
+	Error  *APIError     `json:"error,omitempty"`
+}
+
+// V1ChildrenResult is result of /v1/children
+type V1ChildrenResult struct {
+	Query    string          `json:"query"`
+	Found    bool            `json:"found"` // false if the taxid does not exist
+	Children []V1LineageNode `json:"children"`
+	Error    *APIError       `json:"error,omitempty"`
+}
+
+// V1TaxIDResponse is response of /v1/gi2taxid
+type V1TaxIDResponse struct {
+	V1Envelope
+	Results []V1TaxIDResult `json:"results"`
+}
+
+// V1TaxonResponse is response of /v1/taxid2taxon and /v1/lca
+type V1TaxonResponse struct {
+	V1Envelope
+	Results []V1TaxonResult `json:"results"`
+}
+
+// V1Name2TaxIDResponse is response of /v1/name2taxid
+type V1Name2TaxIDResponse struct {
+	V1Envelope
+	Results []V1Name2TaxIDResult `json:"results"`
+}
+
+// V1ChildrenResponse is response of /v1/children
+type V1ChildrenResponse struct {
+	V1Envelope
+	Results []V1ChildrenResult `json:"results"`
+}
+
+// --------------------------------------------------------------------------
+
+// NewV1Taxon converts Taxon to V1Taxon, nil for missing Taxon (TaxId 0)
+func NewV1Taxon(t nodes.Taxon) *V1Taxon {
+	if t.TaxId == 0 {
+		return nil
+	}
+	otherNames := make([]V1OtherName, len(t.OtherNames))
+	for i, name := range t.OtherNames {
+		otherNames[i] = V1OtherName{NameClass: name.ClassCDE, Name: name.DispName}
+	}
+	return &V1Taxon{
+		TaxID:           t.TaxId,
+		ScientificName:  t.ScientificName,
+		OtherNames:      otherNames,
+		ParentTaxID:     t.ParentTaxId,
+		Rank:            t.Rank,
+		Division:        t.Division,
+		GeneticCode:     V1GeneticCode{ID: t.GeneticCode.GCId, Name: t.GeneticCode.GCName},
+		MitoGeneticCode: V1GeneticCode{ID: t.MitoGeneticCode.MGCId, Name: t.MitoGeneticCode.MGCName},
+		Lineage:         t.Lineage,
+		LineageEx:       newV1Lineage(t.LineageEx),
+	}
+}
+
+// TaxonFromV1 converts V1Taxon back to Taxon, empty Taxon for nil
+func TaxonFromV1(t *V1Taxon) nodes.Taxon {
+	if t == nil {
+		return nodes.Taxon{}
+	}
+	otherNames := make([]nodes.TaxonNameItem, len(t.OtherNames))
+	for i, name := range t.OtherNames {
+		otherNames[i] = nodes.TaxonNameItem{ClassCDE: name.NameClass, DispName: name.Name}
+	}
+	return nodes.Taxon{
+		TaxId:           t.TaxID,
+		ScientificName:  t.ScientificName,
+		OtherNames:      otherNames,
+		ParentTaxId:     t.ParentTaxID,
+		Rank:            t.Rank,
+		Division:        t.Division,
+		GeneticCode:     nodes.GeneticCodeItem{GCId: t.GeneticCode.ID, GCName: t.GeneticCode.Name},
+		MitoGeneticCode: nodes.MitoGeneticCodeItem{MGCId: t.MitoGeneticCode.ID, MGCName: t.MitoGeneticCode.Name},
+		Lineage:         t.Lineage,
+		LineageEx:       LineageFromV1(t.LineageEx),
+	}
+}
+
+func newV1Lineage(items []nodes.LineageExItem) []V1LineageNode {
+	lineage := make([]V1LineageNode, len(items))
+	for i, item := range items {
+		lineage[i] = V1LineageNode{TaxID: item.TaxId, ScientificName: item.ScientificName, Rank: item.Rank}
+	}
+	return lineage
+}
+
+// LineageFromV1 converts lineage nodes of API v1 to LineageExItems
+func LineageFromV1(lineage []V1LineageNode) []nodes.LineageExItem {
+	items := make([]nodes.LineageExItem, len(lineage))
+	for i, node := range lineage {
+		items[i] = nodes.LineageExItem{TaxId: node.TaxID, ScientificName: node.ScientificName, Rank: node.Rank}
+	}
+	return items
+}
+
+// NewV1TaxIDNames converts TaxIDSciNameItems to V1TaxIDNames
+func NewV1TaxIDNames(items []TaxIDSciNameItem) []V1TaxIDName {
+	names := make([]V1TaxIDName, len(items))
+	for i, item := range items {
+		names[i] = V1TaxIDName{TaxID: item.TaxID, ScientificName: item.ScientificName}
+	}
+	return names
+}
+
+// TaxIDNamesFromV1 converts V1TaxIDNames back to TaxIDSciNameItems
+func TaxIDNamesFromV1(names []V1TaxIDName) []TaxIDSciNameItem {
+	items := make([]TaxIDSciNameItem, len(names))
+	for i, name := range names {
+		items[i] = TaxIDSciNameItem{TaxID: name.TaxID, ScientificName: name.ScientificName}
+	}
+	return items
+}
+
+// --------------------------------------------------------------------------
+
+// respondV1 answers results of API v1, in NDJSON if client asks for it.
+// query resolves a chunk of queries to results in the same order.
+func respondV1(c *gin.Context, msg interface{ setError(*APIError) }, queries []string,
+	query func(queries []string) ([]interface{}, error), setResults func(results []interface{})) {
+	if wantsNDJSON(c) {
+		streamNDJSON(c, msg, queries, query)
+		return
+	}
+	results, err := query(queries)
+	if err != nil {
+		respondError(c, msg, err)
+		return
+	}
+	setResults(results)
+	c.JSON(http.StatusOK, msg)
+}
+
+func (t *Taxonomy) gi2taxidV1(c *gin.Context) {
+	msg := V1TaxIDResponse{V1Envelope: V1Envelope{Release: releaseOf(c)}}
+
+	req, err := readGi2TaxidRequest(c)
+	msg.Query = V1Query{DB: req.DB, Queries: req.GIs}
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
+
+	respondV1(c, &msg, req.GIs, func(gis []string) ([]interface{}, error) {
+		taxids, err := t.QueryGi2Taxid(c.Request.Context(), req.DB, gis)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]interface{}, len(gis))
+		for i, gi := range gis {
+			taxid, _ := strconv.Atoi(taxids[i])
+			results[i] = V1TaxIDResult{Query: gi, Found: taxid > 0, TaxID: taxid}
+		}
+		return results, nil
+	}, msg.setResults)
+}
+
+func (msg *V1TaxIDResponse) setResults(results []interface{}) {
+	msg.Status = "OK"
+	msg.Results = make([]V1TaxIDResult, len(results))
+	for i, r := range results {
+		msg.Results[i] = r.(V1TaxIDResult)
+	}
+}
+
+func (t *Taxonomy) taxid2taxonV1(c *gin.Context) {
+	msg := V1TaxonResponse{V1Envelope: V1Envelope{Release: releaseOf(c)}}
+
+	req, err := readTaxid2TaxonRequest(c)
+	msg.Query = V1Query{Queries: req.TaxIDs}
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
+
+	respondV1(c, &msg, req.TaxIDs, func(taxids []string) ([]interface{}, error) {
+		taxons, err := t.QueryTaxid2Taxon(taxids)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]interface{}, len(taxids))
+		for i, taxid := range taxids {
+			taxon := NewV1Taxon(taxons[taxid])
+			results[i] = V1TaxonResult{Query: taxid, Found: taxon != nil, Taxon: taxon}
+		}
+		return results, nil
+	}, msg.setResults)
+}
+
+func (t *Taxonomy) lcaV1(c *gin.Context) {
+	msg := V1TaxonResponse{V1Envelope: V1Envelope{Release: releaseOf(c)}}
+
+	req, err := readLCARequest(c)
+	msg.Query = V1Query{Queries: req.TaxIDs}
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
+
+	respondV1(c, &msg, req.TaxIDs, func(queries []string) ([]interface{}, error) {
+		lcas, err := t.QueryLCA(queries)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]interface{}, len(queries))
+		for i, query := range queries {
+			taxon := NewV1Taxon(lcas[query])
+			results[i] = V1TaxonResult{Query: query, Found: taxon != nil, Taxon: taxon}
+		}
+		return results, nil
+	}, msg.setResults)
+}
+
+func (msg *V1TaxonResponse) setResults(results []interface{}) {
+	msg.Status = "OK"
+	msg.Results = make([]V1TaxonResult, len(results))
+	for i, r := range results {
+		msg.Results[i] = r.(V1TaxonResult)
+	}
+}
+
+func (t *Taxonomy) name2taxidV1(c *gin.Context) {
+	msg := V1Name2TaxIDResponse{V1Envelope: V1Envelope{Release: releaseOf(c)}}
+
+	req, err := readName2TaxIDRequest(c)
+	msg.Query = V1Query{Regexp: req.Regexp, Class: req.Class, Queries: req.Names}
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
+
+	respondV1(c, &msg, req.Names, func(names []string) ([]interface{}, error) {
+		found, err := t.QueryName2TaxID(c.Request.Context(), req.Regexp, req.Class, names)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]interface{}, len(names))
+		for i, name := range names {
+			results[i] = V1Name2TaxIDResult{Query: name, Found: len(found[name]) > 0, TaxIDs: NewV1TaxIDNames(found[name])}
+		}
+		return results, nil
+	}, msg.setResults)
+}
+
+func (msg *V1Name2TaxIDResponse) setResults(results []interface{}) {
+	msg.Status = "OK"
+	msg.Results = make([]V1Name2TaxIDResult, len(results))
+	for i, r := range results {
+		msg.Results[i] = r.(V1Name2TaxIDResult)
+	}
+}
+
+func (t *Taxonomy) childrenV1(c *gin.Context) {
+	msg := V1ChildrenResponse{V1Envelope: V1Envelope{Release: releaseOf(c)}}
+
+	req, err := readChildrenRequest(c)
+	msg.Query = V1Query{Queries: req.TaxIDs}
+	if err != nil {
+		respondError(c, &msg, err)
+		return
+	}
+
+	respondV1(c, &msg, req.TaxIDs, func(taxids []string) ([]interface{}, error) {
+		children, err := t.QueryChildren(taxids)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]interface{}, len(taxids))
+		for i, taxid := range taxids {
+			_, ok := t.Nodes[taxid]
+			results[i] = V1ChildrenResult{Query: taxid, Found: ok, Children: newV1Lineage(children[taxid])}
+		}
+		return results, nil
+	}, msg.setResults)
+}
+
+func (msg *V1ChildrenResponse) setResults(results []interface{}) {
+	msg.Status = "OK"
+	msg.Results = make([]V1ChildrenResult, len(results))
+	for i, r := range results {
+		msg.Results[i] = r.(V1ChildrenResult)
+	}
+}
+
+// --------------------------------------------------------------------------
+
+// taxons returns Taxons of results in map of legacy APIs
+func (msg *V1TaxonResponse) taxons() map[string]nodes.Taxon {
+	if msg.Results == nil {
+		return nil
+	}
+	taxons := make(map[string]nodes.Taxon, len(msg.Results))
+	for _, r := range msg.Results {
+		taxons[r.Query] = TaxonFromV1(r.Taxon)
+	}
+	return taxons
+}
+
+// legacyTaxID formats taxid as in legacy APIs, empty string for missing one
+func legacyTaxID(taxid int) string {
+	if taxid == 0 {
+		return ""
+	}
+	return strconv.Itoa(taxid)
+}
+
+// NegotiateAPIVersion asks server for supported versions of REST API.
+// It returns APIVersion if supported by server, or empty string for
+// servers only supporting legacy APIs. http.DefaultClient is used if
+// client is nil.
+func NegotiateAPIVersion(ctx context.Context, client *http.Client, baseURL string) (string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequest("GET", remoteURL(baseURL, "/versions"), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	var msg MessageAPIVersions
+	if err = json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return "", fmt.Errorf("invalid response from server: %s", err)
+	}
+	for _, version := range msg.Versions {
+		if version == APIVersion {
+			return version, nil
+		}
+	}
+	return "", nil
+}